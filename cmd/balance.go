@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "Propose swaps to balance on-call shift counts",
+	Long: `This command counts on-call shifts in the same way as the count subcommand and proposes overrides
+handing over future shifts so that each user's projected count is within the tolerance of the mean.
+Shifts are generated by the configuration of the count subcommand, and the users are those in the schedules.`,
+	Args:    cobra.NoArgs,
+	GroupID: defaultCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, shiftGeneratorKeys...)
+		inheritCountConfig(v, "schedule-ids")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		sg, err := newShiftGenerator(v, tz)
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runBalance(
+			cmd.Context(),
+			os.Stdout,
+			client,
+			tz,
+			v.GetStringSlice("schedule-ids"),
+			sg,
+			v.GetStringSlice("unavailable"),
+			v.GetFloat64("tolerance"),
+			time.Now(),
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(balanceCmd)
+
+	balanceCmd.Flags().StringSlice("schedule-ids", []string{}, "List of scheduled IDs to balance (default count.schedule-ids)")
+	balanceCmd.Flags().String("since", "", "Start of the date range for counting on-call shifts")
+	balanceCmd.Flags().String("until", "", "End of the date range for counting on-call shifts")
+	balanceCmd.Flags().String("period", "", "Period for counting on-call shifts instead of since and until (e.g. this-month, 2025-Q4)")
+	balanceCmd.MarkFlagsOneRequired("since", "period")
+	balanceCmd.MarkFlagsOneRequired("until", "period")
+	balanceCmd.Flags().Float64("tolerance", 1, "Allowed difference between each user's projected count and the mean")
+	balanceCmd.Flags().StringSlice("unavailable", []string{}, "List of unavailable dates in the format <name>:<date> or <name>:<start date>/<end date>")
+}
+
+func runBalance(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleIDs []string, sg *pd.ShiftGenerator, unavailable []string, tolerance float64, now time.Time) error {
+	schedules, err := getSchedules(ctx, client, tz, scheduleIDs, sg)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[string]string)
+	iters := make([]*pd.ScheduleEntryIter, len(schedules))
+	for i, schedule := range schedules {
+		for _, entry := range schedule.FinalSchedule.RenderedScheduleEntries {
+			userIDs[entry.User.Summary] = entry.User.ID
+		}
+		iters[i], err = pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
+		if err != nil {
+			return err
+		}
+	}
+
+	participants := make([]*pd.Participant, 0, len(userIDs))
+	for _, name := range slices.Sorted(maps.Keys(userIDs)) {
+		participants = append(participants, &pd.Participant{Name: name, UserID: userIDs[name]})
+	}
+	if err := addUnavailableDates(participants, unavailable); err != nil {
+		return err
+	}
+
+	shifts := make([]*pd.Shift, 0)
+	for shift := range sg.AllShifts() {
+		if shift.Weight == 0 {
+			continue
+		}
+		for _, iter := range iters {
+			shift.AddDetails(iter)
+		}
+		shifts = append(shifts, shift)
+	}
+
+	proposal, err := pd.ProposeSwaps(shifts, now, participants, tolerance)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "# Projected counts\n\n")
+	fmt.Fprintf(out, "| User | Current | Proposed |\n")
+	fmt.Fprintf(out, "|------|------|------|\n")
+	for _, p := range participants {
+		fmt.Fprintf(out, "| %s | %0.2f | %0.2f |\n", p.Name, proposal.Current[p.Name], proposal.Projected[p.Name])
+	}
+	fmt.Fprintf(out, "| Mean | %0.2f | %0.2f |\n", proposal.Mean, proposal.Mean)
+
+	fmt.Fprintf(out, "\n# Proposed swaps\n\n")
+	if len(proposal.Swaps) == 0 {
+		fmt.Fprintf(out, "No swaps are proposed.\n")
+	}
+	for _, swap := range proposal.Swaps {
+		fmt.Fprintf(out, "- %s - %s (%s): %s -> %s (%0.2f)\n",
+			swap.Shift.Start.Format(dateTimeLayout), swap.Shift.End.Format(dateTimeLayout), swap.ScheduleName, swap.From.Name, swap.To.Name, swap.Weight)
+	}
+	if !proposal.Balanced {
+		fmt.Fprintf(out, "\nSome users are still out of the tolerance %v because no more future shifts can be swapped.\n", tolerance)
+	}
+
+	return nil
+}
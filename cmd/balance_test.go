@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/abicky/pd-shift/testing/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_runBalance(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		unavailable []string
+		tolerance   float64
+		wantOutput  string
+	}{
+		{
+			name:      "Balanced within tolerance",
+			tolerance: 1,
+			wantOutput: `# Projected counts
+
+| User | Current | Proposed |
+|------|------|------|
+| John Smith | 7.50 | 5.50 |
+| Takeshi Arabiki | 1.50 | 3.50 |
+| Mean | 4.50 | 4.50 |
+
+# Proposed swaps
+
+- Fri, 2025-07-04 17:00+0900 - Sat, 2025-07-05 05:00+0900 (Weekly Rotation): John Smith -> Takeshi Arabiki (1.00)
+- Sat, 2025-07-05 17:00+0900 - Sun, 2025-07-06 05:00+0900 (Weekly Rotation): John Smith -> Takeshi Arabiki (1.00)
+`,
+		},
+		{
+			name:        "Unavailable dates",
+			unavailable: []string{"Takeshi Arabiki:2025-07-04/2025-07-05"},
+			tolerance:   0,
+			wantOutput: `# Projected counts
+
+| User | Current | Proposed |
+|------|------|------|
+| John Smith | 7.50 | 5.50 |
+| Takeshi Arabiki | 1.50 | 3.50 |
+| Mean | 4.50 | 4.50 |
+
+# Proposed swaps
+
+- Sun, 2025-07-06 05:00+0900 - Sun, 2025-07-06 17:00+0900 (Weekly Rotation): John Smith -> Takeshi Arabiki (1.00)
+- Sun, 2025-07-06 17:00+0900 - Mon, 2025-07-07 05:00+0900 (Weekly Rotation): John Smith -> Takeshi Arabiki (1.00)
+
+Some users are still out of the tolerance 0 because no more future shifts can be swapped.
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sg, err := pd.NewShiftGenerator(jst, "2025-07-01", "2025-07-08", []string{"05:00", "17:00"}, []string{"working-days:17:00-05:00", "non-working-days"}, []string{"JP holidays", "Sat", "Sun"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start, end := sg.Period()
+			client := mock.NewMockClient(ctrl)
+			client.EXPECT().GetScheduleWithContext(t.Context(), "P4DRALL", pagerduty.GetScheduleOptions{
+				TimeZone: jst.String(),
+				Since:    start.Format("2006-01-02 15:04"),
+				Until:    end.Format("2006-01-02 15:04"),
+			}).Return(&pagerduty.Schedule{
+				Name: "Weekly Rotation",
+				FinalSchedule: pagerduty.ScheduleLayer{
+					RenderedScheduleEntries: []pagerduty.RenderedScheduleEntry{
+						{
+							Start: "2025-07-01T05:00:00+09:00",
+							End:   "2025-07-05T09:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PJOHN12", Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-05T09:00:00+09:00",
+							End:   "2025-07-05T15:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+						},
+						{
+							Start: "2025-07-05T15:00:00+09:00",
+							End:   "2025-07-07T05:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PJOHN12", Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-07T05:00:00+09:00",
+							End:   "2025-07-08T05:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+						},
+					},
+				},
+			}, nil)
+
+			var b bytes.Buffer
+
+			now := time.Date(2025, time.July, 4, 0, 0, 0, 0, jst)
+			if err := runBalance(t.Context(), &b, client, jst, []string{"P4DRALL"}, sg, tt.unavailable, tt.tolerance, now); err != nil {
+				t.Errorf("runBalance() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
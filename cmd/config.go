@@ -0,0 +1,561 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Short:   "Manage the configuration",
+	Args:    cobra.NoArgs,
+	GroupID: auxiliaryCommandGroup.ID,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration",
+	Long: `This command validates the configuration loaded from the config file, environment variables, and flags.
+Unknown keys in the config file including profiles and unknown PD_SHIFT_* environment variables are reported as warnings,
+and invalid values such as typos in count.include are reported as errors.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		return runValidateConfig(os.Stdout, viper.ConfigFileUsed(), os.Environ())
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [<subcommand>...]",
+	Short: "Show the effective configuration",
+	Long: `This command shows the resolved settings of the root command and the given subcommand
+together with their sources: a flag, an environment variable, the config file, or the default.
+Flags of the subcommand are not taken into account because they are given only when it runs. api-key is redacted.`,
+	Example: "  pd-shift config show count\n  pd-shift config show override create",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		target, err := findSubcommand(rootCmd, args)
+		if err != nil {
+			return err
+		}
+
+		return runShowConfig(os.Stdout, viper.GetViper(), target)
+	},
+}
+
+// configOnlyKeys are the keys of each subcommand not bound to flags
+var configOnlyKeys = map[string][]string{
+	"count":    {"day-classes"},
+	"holidays": {"day-classes"},
+	"report":   {"definitions"},
+}
+
+// inheritedCountKeys are the keys each subcommand inherits from the count subcommand by inheritCountConfig
+var inheritedCountKeys = map[string][]string{
+	"holidays":        {"non-working-days", "day-classes"},
+	"plan":            shiftGeneratorKeys,
+	"balance":         append(slices.Clone(shiftGeneratorKeys), "schedule-ids"),
+	"swap":            shiftGeneratorKeys,
+	"forecast":        append(slices.Clone(shiftGeneratorKeys), "schedule-ids"),
+	"override.create": {"time-zone"},
+	"override.list":   {"time-zone"},
+	"ledger":          {"time-zone", "schedule-ids"},
+	"ledger.adjust":   {"schedule-ids"},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd, configShowCmd)
+}
+
+// configKeys returns the keys that can be configured and the sections of subcommands, which are joined by "."
+func configKeys(root *cobra.Command) (map[string]bool, map[string]bool) {
+	keys := make(map[string]bool)
+	sections := make(map[string]bool)
+	root.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		keys[f.Name] = true
+	})
+
+	var walk func(cmd *cobra.Command, prefix string)
+	walk = func(cmd *cobra.Command, prefix string) {
+		for _, c := range cmd.Commands() {
+			section := prefix + strings.Split(c.Use, " ")[0]
+			sections[section] = true
+			c.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+				keys[section+"."+f.Name] = true
+			})
+			for _, key := range slices.Concat(configOnlyKeys[section], inheritedCountKeys[section]) {
+				keys[section+"."+key] = true
+			}
+			walk(c, section+".")
+		}
+	}
+	walk(root, "")
+
+	return keys, sections
+}
+
+// unknownConfigKeys returns the keys in settings not in keys in sorted order
+func unknownConfigKeys(settings map[string]any, prefix string, keys, sections map[string]bool) []string {
+	unknown := make([]string, 0)
+	for _, k := range slices.Sorted(maps.Keys(settings)) {
+		key := prefix + k
+		if keys[key] {
+			continue
+		}
+		if m, ok := settings[k].(map[string]any); ok && sections[key] {
+			unknown = append(unknown, unknownConfigKeys(m, key+".", keys, sections)...)
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	return unknown
+}
+
+// configKeyLines returns the line numbers of the keys in a YAML document, which are lowercased like Viper
+func configKeyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := prefix + strings.ToLower(node.Content[i].Value)
+			lines[key] = node.Content[i].Line
+			walk(node.Content[i+1], key+".")
+		}
+	}
+	walk(doc.Content[0], "")
+
+	return lines
+}
+
+// checkConfigFile returns warnings about unknown keys in the config file
+func checkConfigFile(root *cobra.Command, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var lines map[string]int
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		lines = configKeyLines(data)
+	}
+
+	keys, sections := configKeys(root)
+	settings := v.AllSettings()
+	profiles, _ := settings["profiles"].(map[string]any)
+	delete(settings, "profiles")
+	unknown := unknownConfigKeys(settings, "", keys, sections)
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		profile, ok := profiles[name].(map[string]any)
+		if !ok {
+			unknown = append(unknown, "profiles."+name)
+			continue
+		}
+		for _, key := range unknownConfigKeys(profile, "", keys, sections) {
+			if key != "inherits" {
+				unknown = append(unknown, "profiles."+name+"."+key)
+			}
+		}
+	}
+
+	warnings := make([]string, 0)
+	for _, key := range unknown {
+		if line, ok := lines[key]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: unknown key %q", path, line, key))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown key %q", path, key))
+		}
+	}
+	return warnings, nil
+}
+
+// checkEnv returns warnings about environment variables with the prefix not corresponding to any key
+func checkEnv(root *cobra.Command, environ []string) []string {
+	prefix := envName("")
+	keys, _ := configKeys(root)
+	names := make(map[string]bool, len(keys))
+	for key := range keys {
+		names[envName(key)] = true
+	}
+
+	warnings := make([]string, 0)
+	for _, env := range environ {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, prefix) && !names[name] {
+			warnings = append(warnings, fmt.Sprintf("unknown environment variable %s", name))
+		}
+	}
+	slices.Sort(warnings)
+	return warnings
+}
+
+// envName returns the name of the environment variable for the key in the same way as initConfig
+func envName(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(toolName + "_" + key))
+}
+
+// shiftGeneratorValidationKeys are the keys used to generate shifts in the order they are validated.
+// Each key is validated together with the valid keys before it so that errors are reported for the key causing them.
+var shiftGeneratorValidationKeys = []string{
+	"handoff-times",
+	"non-working-days",
+	"day-classes",
+	"day-type-anchor",
+	"handoff-times-by-day",
+	"handoff-cycle-days",
+	"handoff-cycle-anchor",
+	"include",
+	"exclude",
+	"select",
+	"shift-labels",
+}
+
+// configKeyError is an error in the value of a key
+type configKeyError struct {
+	key string
+	err error
+}
+
+func (e *configKeyError) Error() string {
+	return e.key + ": " + e.err.Error()
+}
+
+func (e *configKeyError) Unwrap() error {
+	return e.err
+}
+
+// validateShiftGeneratorConfig returns all the problems in the configuration used to generate shifts.
+// If neither the period nor since and until are specified, shifts are generated for the current date.
+func validateShiftGeneratorConfig(v *viper.Viper) []error {
+	tz, err := time.LoadLocation(v.GetString("time-zone"))
+	if err != nil {
+		return []error{&configKeyError{key: "time-zone", err: err}}
+	}
+
+	errs := make([]error, 0)
+	since, until, err := getPeriod(v, tz)
+	if err != nil {
+		errs = append(errs, &configKeyError{key: "period", err: err})
+	} else {
+		if _, err := pd.ParseTime(since, tz); since != "" && err != nil {
+			errs = append(errs, &configKeyError{key: "since", err: err})
+			since = ""
+		}
+		if _, err := pd.ParseTime(until, tz); until != "" && err != nil {
+			errs = append(errs, &configKeyError{key: "until", err: err})
+			until = ""
+		}
+	}
+	if since == "" || until == "" {
+		today := time.Now().In(tz)
+		since = today.Format(time.DateOnly)
+		until = today.AddDate(0, 0, 1).Format(time.DateOnly)
+	}
+
+	valid := make(map[string]any)
+	for _, key := range shiftGeneratorValidationKeys {
+		_, hasHandoffTimes := valid["handoff-times"]
+		// The keys other than them cannot be validated without valid handoff times
+		if !hasHandoffTimes && key != "handoff-times" && key != "non-working-days" && key != "day-classes" && key != "day-type-anchor" {
+			continue
+		}
+
+		check := viper.New()
+		check.SetDefault("day-type-anchor", "start")
+		if key != "handoff-times" && !hasHandoffTimes {
+			check.Set("handoff-times", []string{"00:00"})
+		}
+		for k, value := range valid {
+			check.Set(k, value)
+		}
+		value := v.Get(key)
+		if value != nil {
+			check.Set(key, value)
+		}
+		if _, err := newShiftGeneratorInPeriod(check, tz, since, until); err != nil {
+			errs = append(errs, &configKeyError{key: key, err: err})
+			continue
+		}
+		if value != nil {
+			valid[key] = value
+		}
+	}
+	return errs
+}
+
+// formatConfigKeyError formats the error in the section with the key and its location in the config file if found.
+// lines are the line numbers of the keys in the config file, and the key may be inherited from the count section.
+func formatConfigKeyError(section string, err error, path string, lines map[string]int, profile string) string {
+	var keyErr *configKeyError
+	if !errors.As(err, &keyErr) {
+		return section + ": " + err.Error()
+	}
+	key := section + "." + keyErr.key
+	candidates := []string{key, "count." + keyErr.key}
+	if profile != "" {
+		candidates = []string{"profiles." + profile + "." + key, key, "profiles." + profile + ".count." + keyErr.key, "count." + keyErr.key}
+	}
+	if _, ok := os.LookupEnv(envName(key)); !ok {
+		for _, k := range candidates {
+			if line, ok := lines[k]; ok {
+				return fmt.Sprintf("%s:%d: %s: %s", path, line, key, keyErr.err)
+			}
+		}
+	}
+	return key + ": " + keyErr.err.Error()
+}
+
+func runValidateConfig(out io.Writer, path string, environ []string) error {
+	warnings := make([]string, 0)
+	errs := make([]string, 0)
+
+	if path == "" {
+		fmt.Fprintln(out, "No config file is found.")
+	} else if w, err := checkConfigFile(rootCmd, path); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		warnings = append(warnings, w...)
+	}
+	warnings = append(warnings, checkEnv(rootCmd, environ)...)
+
+	if profiles, ok := viper.Get("profiles").(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(profiles)) {
+			if _, err := profileSettings(viper.GetViper(), name); err != nil {
+				errs = append(errs, "profiles."+name+": "+err.Error())
+			}
+		}
+	}
+
+	var lines map[string]int
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		if data, err := os.ReadFile(path); err == nil {
+			lines = configKeyLines(data)
+		}
+	}
+	profile := strings.ToLower(viper.GetString("profile"))
+
+	for _, err := range validateShiftGeneratorConfig(vipers[countCmd]) {
+		errs = append(errs, formatConfigKeyError("count", err, path, lines, profile))
+	}
+	if _, err := getFTE(vipers[countCmd]); err != nil {
+		errs = append(errs, "count: "+err.Error())
+	}
+	// Subcommands inheriting the count configuration are validated only if they override it
+	for _, cmd := range []*cobra.Command{planCmd, balanceCmd, forecastCmd} {
+		v := vipers[cmd]
+		if !slices.ContainsFunc(shiftGeneratorKeys, v.IsSet) {
+			continue
+		}
+		inheritCountConfig(v, shiftGeneratorKeys...)
+		for _, err := range validateShiftGeneratorConfig(v) {
+			errs = append(errs, formatConfigKeyError(cmd.Name(), err, path, lines, profile))
+		}
+	}
+	if _, err := getDayClasses(vipers[holidaysCmd]); err != nil {
+		errs = append(errs, "holidays: "+err.Error())
+	}
+	if v := vipers[reportCmd]; v.IsSet("definitions") {
+		// The period is given only when the report runs
+		today := time.Now()
+		rv := viper.New()
+		rv.Set("definitions", v.Get("definitions"))
+		rv.Set("since", today.Format(time.DateOnly))
+		rv.Set("until", today.AddDate(0, 0, 1).Format(time.DateOnly))
+		if _, err := newReportDefinitions(rv); err != nil {
+			errs = append(errs, "report: "+err.Error())
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(out, "warning: %s\n", w)
+	}
+	for _, e := range errs {
+		fmt.Fprintf(out, "error: %s\n", e)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("found %d errors in the configuration", len(errs))
+	}
+	if len(warnings) > 0 {
+		fmt.Fprintf(out, "The configuration is valid with %d warnings.\n", len(warnings))
+	} else {
+		fmt.Fprintln(out, "The configuration is valid.")
+	}
+	return nil
+}
+
+// findSubcommand returns the subcommand of root specified by names
+func findSubcommand(root *cobra.Command, names []string) (*cobra.Command, error) {
+	cmd := root
+	for i, name := range names {
+		j := slices.IndexFunc(cmd.Commands(), func(c *cobra.Command) bool {
+			return c.Name() == name
+		})
+		if j == -1 {
+			return nil, fmt.Errorf("unknown subcommand %q", strings.Join(names[:i+1], " "))
+		}
+		cmd = cmd.Commands()[j]
+	}
+	return cmd, nil
+}
+
+// configFile is the settings in the config file and the profile merged into them
+type configFile struct {
+	path     string
+	settings *viper.Viper
+	profile  string
+	// profileSettings is the settings of the profile, which take precedence over settings
+	profileSettings *viper.Viper
+}
+
+func newConfigFile(v *viper.Viper) (*configFile, error) {
+	c := &configFile{path: v.ConfigFileUsed(), settings: viper.New(), profile: v.GetString("profile"), profileSettings: viper.New()}
+	if c.path != "" {
+		c.settings.SetConfigFile(c.path)
+		if err := c.settings.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("%s: %w", c.path, err)
+		}
+	}
+	if c.profile != "" {
+		settings, err := profileSettings(c.settings, c.profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.profileSettings.MergeConfigMap(settings); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// source returns where the value of the flag or the key without a flag comes from
+func (c *configFile) source(f *pflag.Flag, key string) string {
+	if f != nil && f.Changed && !configuredFlags[f] {
+		return "flag --" + f.Name
+	}
+	if value, ok := os.LookupEnv(envName(key)); ok && value != "" {
+		return "env " + envName(key)
+	}
+	if c.profileSettings.IsSet(key) {
+		return fmt.Sprintf("profile %s in %s", c.profile, c.path)
+	}
+	if c.settings.IsSet(key) {
+		return "config " + c.path
+	}
+	return "default"
+}
+
+// formatConfigValue formats the value in JSON, which distinguishes strings from lists and maps
+func formatConfigValue(value any) string {
+	if value == nil {
+		return "null"
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func runShowConfig(out io.Writer, v *viper.Viper, cmd *cobra.Command) error {
+	config, err := newConfigFile(v)
+	if err != nil {
+		return err
+	}
+	if config.path != "" {
+		fmt.Fprintf(out, "- Config file: %s\n", config.path)
+	} else {
+		fmt.Fprintln(out, "- Config file: none")
+	}
+	if config.profile != "" {
+		fmt.Fprintf(out, "- Profile: %s\n", config.profile)
+	}
+
+	printSetting := func(key string, f *pflag.Flag, value any) {
+		if key == "api-key" && value != "" {
+			value = "<redacted>"
+		}
+		fmt.Fprintf(out, "- %s: %s (%s)\n", key, formatConfigValue(value), config.source(f, key))
+	}
+
+	fmt.Fprintf(out, "\n# %s\n\n", cmd.Root().Name())
+	cmd.Root().PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		printSetting(f.Name, f, v.Get(f.Name))
+	})
+	if cmd == cmd.Root() {
+		return nil
+	}
+
+	section := strings.ReplaceAll(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "), " ", ".")
+	fmt.Fprintf(out, "\n# %s\n\n", strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "))
+	subv := vipers[cmd]
+	if subv == nil {
+		subv = viper.New()
+	}
+	names := make([]string, 0)
+	flags := make(map[string]*pflag.Flag)
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" {
+			return
+		}
+		names = append(names, f.Name)
+		flags[f.Name] = f
+	})
+	for _, key := range slices.Concat(configOnlyKeys[section], inheritedCountKeys[section]) {
+		if !slices.Contains(names, key) {
+			names = append(names, key)
+		}
+	}
+	slices.Sort(names)
+	countv := vipers[countCmd]
+	if countv == nil {
+		countv = viper.New()
+	}
+	for _, name := range names {
+		if slices.Contains(inheritedCountKeys[section], name) && !subv.IsSet(name) {
+			source := config.source(countCmd.Flags().Lookup(name), "count."+name)
+			fmt.Fprintf(out, "- %s.%s: %s (inherited from count.%s, %s)\n", section, name, formatConfigValue(countv.Get(name)), name, source)
+			continue
+		}
+		value := subv.Get(name)
+		if f := flags[name]; f != nil && f.Value.Type() == "float64" {
+			// Viper returns the default values of float flags as strings
+			value = subv.GetFloat64(name)
+		}
+		printSetting(section+"."+name, flags[name], value)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Test_checkConfigFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		content      string
+		wantWarnings []string
+	}{
+		{
+			name: "YAML",
+			file: "config.yaml",
+			content: `api-key: xxx
+count:
+  timezone: Asia/Tokyo
+  schedule-ids: [P4DRALL]
+  shift-labels:
+    05:00-17:00: day
+  day-classes:
+    - name: holidays
+      days: [JP holidays]
+override:
+  create:
+    apply: true
+    Schedule: PXXXXXX
+plan:
+  include: [non-working-days]
+ledger:
+  schedule-ids: [P4DRALL]
+  adjust:
+    schedule-ids: [P4DRALL]
+unknown: 1
+profiles:
+  sre:
+    count:
+      schedule-id: P4DRALL
+  sre-weekend:
+    inherits: sre
+    count:
+      include: [non-working-days]
+`,
+			wantWarnings: []string{
+				`CONFIG:3: unknown key "count.timezone"`,
+				`CONFIG:13: unknown key "override.create.schedule"`,
+				`CONFIG:20: unknown key "unknown"`,
+				`CONFIG:24: unknown key "profiles.sre.count.schedule-id"`,
+			},
+		},
+		{
+			name:    "JSON",
+			file:    "config.json",
+			content: `{"count": {"schedule-ids": ["P4DRALL"], "non-working-day": ["Sat"]}}`,
+			wantWarnings: []string{
+				`CONFIG: unknown key "count.non-working-day"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := checkConfigFile(rootCmd, path)
+			if err != nil {
+				t.Fatalf("checkConfigFile() = %v, want nil", err)
+			}
+			want := make([]string, len(tt.wantWarnings))
+			for i, w := range tt.wantWarnings {
+				want[i] = path + w[len("CONFIG"):]
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("checkConfigFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_checkEnv(t *testing.T) {
+	environ := []string{
+		"HOME=/root",
+		"PD_SHIFT_API_KEY=xxx",
+		"PD_SHIFT_COUNT_TIME_ZONE=Asia/Tokyo",
+		"PD_SHIFT_COUNT_TIMEZONE=Asia/Tokyo",
+		"PD_SHIFT_OVERRIDE_CREATE_SCHEDULE_ID=P4DRALL",
+		"PD_SHIFT_APIKEY=xxx",
+	}
+	want := []string{
+		"unknown environment variable PD_SHIFT_APIKEY",
+		"unknown environment variable PD_SHIFT_COUNT_TIMEZONE",
+	}
+	if got := checkEnv(rootCmd, environ); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkEnv() = %v, want %v", got, want)
+	}
+}
+
+func Test_validateShiftGeneratorConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		wantErrs []string
+	}{
+		{
+			name: "Valid",
+			config: map[string]any{
+				"handoff-times":    []string{"05:00", "17:00"},
+				"include":          []string{"working-days:17:00-05:00", "non-working-days"},
+				"non-working-days": []string{"JP holidays", "Sat", "Sun"},
+			},
+		},
+		{
+			name: "Invalid time zone",
+			config: map[string]any{
+				"time-zone":     "Asia/Tokio",
+				"handoff-times": []string{"05:00"},
+			},
+			wantErrs: []string{"time-zone: unknown time zone Asia/Tokio"},
+		},
+		{
+			name: "Multiple problems",
+			config: map[string]any{
+				"handoff-times": []string{"05:00"},
+				"include":       []string{"working-dayz"},
+				"period":        "2025-Q5",
+			},
+			wantErrs: []string{`period: unknown period "2025-Q5"`, `include: unknown include type "working-dayz"`},
+		},
+		{
+			name: "Problems in keys depending on each other",
+			config: map[string]any{
+				"handoff-times":    []string{"05:00", "17:00"},
+				"non-working-days": []string{"Sat", "Sun"},
+				"day-classes":      []map[string]any{{"name": "working-days", "days": []string{"Sat"}}},
+				"include":          []string{"workng-days"},
+				"exclude":          []string{"non-working-days:05:00-09:00"},
+			},
+			wantErrs: []string{
+				`day-classes: invalid day class name "working-days"`,
+				`include: unknown include type "workng-days"`,
+				`exclude: invalid exclude condition: end time in the include condition "non-working-days:05:00-09:00" must match one of handoff times`,
+			},
+		},
+		{
+			name: "Invalid handoff times",
+			config: map[string]any{
+				"handoff-times":   []string{"5 PM"},
+				"include":         []string{"working-days:17:00-05:00"},
+				"day-type-anchor": "begin",
+			},
+			wantErrs: []string{`handoff-times: invalid weekday of handoff time "5 PM"`, `day-type-anchor: unknown day type anchor "begin"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.SetDefault("time-zone", "UTC")
+			v.SetDefault("day-type-anchor", "start")
+			v.SetDefault("handoff-cycle-days", 1)
+			v.SetDefault("fiscal-year-start", 4)
+			for key, value := range tt.config {
+				v.Set(key, value)
+			}
+
+			got := make([]string, 0)
+			for _, err := range validateShiftGeneratorConfig(v) {
+				got = append(got, err.Error())
+			}
+			if want := append([]string{}, tt.wantErrs...); !reflect.DeepEqual(got, want) {
+				t.Errorf("validateShiftGeneratorConfig() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_formatConfigKeyError(t *testing.T) {
+	lines := map[string]int{
+		"count.include":              3,
+		"plan.exclude":               8,
+		"profiles.sre.count.include": 12,
+	}
+	tests := []struct {
+		name    string
+		section string
+		err     error
+		profile string
+		want    string
+	}{
+		{
+			name:    "Key in the section",
+			section: "count",
+			err:     &configKeyError{key: "include", err: errors.New("invalid")},
+			want:    "c.yaml:3: count.include: invalid",
+		},
+		{
+			name:    "Key inherited from count",
+			section: "plan",
+			err:     &configKeyError{key: "include", err: errors.New("invalid")},
+			want:    "c.yaml:3: plan.include: invalid",
+		},
+		{
+			name:    "Key overriding count",
+			section: "plan",
+			err:     &configKeyError{key: "exclude", err: errors.New("invalid")},
+			want:    "c.yaml:8: plan.exclude: invalid",
+		},
+		{
+			name:    "Key in the profile",
+			section: "count",
+			err:     &configKeyError{key: "include", err: errors.New("invalid")},
+			profile: "sre",
+			want:    "c.yaml:12: count.include: invalid",
+		},
+		{
+			name:    "Key not in the config file",
+			section: "count",
+			err:     &configKeyError{key: "non-working-days", err: errors.New("invalid")},
+			want:    "count.non-working-days: invalid",
+		},
+		{
+			name:    "Error not related to a key",
+			section: "count",
+			err:     errors.New("invalid"),
+			want:    "count: invalid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatConfigKeyError(tt.section, tt.err, "c.yaml", lines, tt.profile); got != tt.want {
+				t.Errorf("formatConfigKeyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runShowConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `api-key: xxx
+count:
+  schedule-ids: [P4DRALL]
+  since: 2025-07-01
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PD_SHIFT_COUNT_SINCE", "2025-08-01")
+
+	root := &cobra.Command{Use: "pd-shift"}
+	root.PersistentFlags().String("config", "", "")
+	root.PersistentFlags().String("api-key", "", "")
+	sub := &cobra.Command{Use: "count"}
+	sub.Flags().String("time-zone", "UTC", "")
+	sub.Flags().String("since", "", "")
+	sub.Flags().StringSlice("schedule-ids", []string{}, "")
+	sub.Flags().Float64("tolerance", 1, "")
+	root.AddCommand(sub)
+	if err := root.ParseFlags([]string{"--config", path}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.SetEnvPrefix(toolName)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	v.AutomaticEnv()
+	if err := v.BindPFlags(root.PersistentFlags()); err != nil {
+		t.Fatal(err)
+	}
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := bindPFlags(v, root); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(vipers, sub)
+
+	var b bytes.Buffer
+	if err := runShowConfig(&b, v, sub); err != nil {
+		t.Fatalf("runShowConfig() = %v, want nil", err)
+	}
+	want := strings.ReplaceAll(`- Config file: CONFIG
+
+# pd-shift
+
+- api-key: "<redacted>" (config CONFIG)
+- config: "CONFIG" (flag --config)
+
+# count
+
+- count.day-classes: null (default)
+- count.schedule-ids: ["P4DRALL"] (config CONFIG)
+- count.since: "2025-08-01" (env PD_SHIFT_COUNT_SINCE)
+- count.time-zone: "UTC" (default)
+- count.tolerance: 1 (default)
+`, "CONFIG", path)
+	if b.String() != want {
+		t.Errorf("b.String() = %v, want %v", b.String(), want)
+	}
+}
+
+func Test_findSubcommand(t *testing.T) {
+	got, err := findSubcommand(rootCmd, []string{"override", "create"})
+	if err != nil || got != overrideCreateCmd {
+		t.Errorf("findSubcommand() = %v, %v, want %v, nil", got, err, overrideCreateCmd)
+	}
+	if _, err := findSubcommand(rootCmd, []string{"override", "update"}); err == nil || err.Error() != `unknown subcommand "override update"` {
+		t.Errorf("findSubcommand() = %v, want an error", err)
+	}
+}
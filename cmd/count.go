@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"math"
 	"net/http"
 	"os"
+	"reflect"
 	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
@@ -17,7 +21,10 @@ import (
 	"github.com/spf13/viper"
 )
 
-const dateTimeLayout = "Mon, 2006-01-02 15:04-0700"
+const (
+	dateTimeLayout = "Mon, 2006-01-02 15:04-0700"
+	apiTimeLayout  = "2006-01-02 15:04"
+)
 
 var countCmd = &cobra.Command{
 	Use:   "count",
@@ -37,30 +44,51 @@ For full configuration details, refer to https://github.com/abicky/pd-shift#conf
 			return err
 		}
 
-		handoffTimes := v.GetStringSlice("handoff-times")
-		slices.Sort(handoffTimes)
-
-		since := v.GetString("since")
-		until := v.GetString("until")
+		sg, err := newShiftGenerator(v, tz)
+		if err != nil {
+			return err
+		}
 
-		sg, err := pd.NewShiftGenerator(tz, since, until, handoffTimes, v.GetStringSlice("include"), v.GetStringSlice("non-working-days"))
+		fte, err := getFTE(v)
 		if err != nil {
 			return err
 		}
 
+		var path string
+		if v.GetBool("record") {
+			if _, end := sg.Period(); end.After(time.Now()) {
+				return fmt.Errorf("cannot record the counts of the period not ended yet: %s", end.Format(dateTimeLayout))
+			}
+			path, err = ledgerFile()
+			if err != nil {
+				return err
+			}
+		}
+
 		client := pagerduty.NewClient(viper.GetString("api-key"))
 
-		return runCount(
+		if err := runCount(
 			cmd.Context(),
 			os.Stdout,
 			client,
 			tz,
-			// Align time to the first handoff time to include entire shifts
-			since+" "+handoffTimes[0],
-			until+" "+handoffTimes[0],
 			v.GetStringSlice("schedule-ids"),
 			sg,
-		)
+			countOptions{
+				verbose:    v.GetBool("verbose"),
+				bucket:     v.GetString("bucket"),
+				fairness:   v.GetBool("fairness"),
+				fte:        fte,
+				ledgerPath: path,
+				ledgerKey:  ledgerKey(v),
+			},
+		); err != nil {
+			return err
+		}
+		if path != "" {
+			fmt.Fprintf(os.Stderr, "Recorded the counts in %s\n", path)
+		}
+		return nil
 	},
 }
 
@@ -72,33 +100,345 @@ func init() {
 	countCmd.MarkFlagRequired("schedule-ids")
 	countCmd.Flags().StringSlice("handoff-times", []string{}, "List of handoff times")
 	countCmd.MarkFlagRequired("handoff-times")
+	countCmd.Flags().Int("handoff-cycle-days", 1, "Number of days between days with handoffs (e.g. 7 for weekly shifts)")
+	countCmd.Flags().String("handoff-cycle-anchor", "", "Date from which handoff-cycle-days is counted (default: since)")
+	countCmd.Flags().StringToString("handoff-times-by-day", map[string]string{}, "Space-separated handoff times for specific weekdays or day classes (e.g. Sat=09:00,Sun=09:00)")
 	countCmd.Flags().StringSlice("include", []string{}, "List of shifts to count")
+	countCmd.Flags().StringToString("shift-labels", map[string]string{}, "Labels of shifts between handoff times (e.g. 05:00-17:00=day,17:00-05:00=night)")
+	countCmd.Flags().String("select", "", "Boolean expression to select shifts to count in addition to include")
+	countCmd.Flags().StringSlice("exclude", []string{}, "List of shifts not to count even if they match include")
 	countCmd.Flags().StringSlice("non-working-days", []string{}, "List of non-working days used by include")
-	countCmd.Flags().String("since", "", "Start of the date range for counting on-call shifts")
-	countCmd.MarkFlagRequired("since")
+	countCmd.Flags().String("day-type-anchor", "start", "How to decide the day type of a shift spanning multiple days (start, end, majority, or split)")
+	countCmd.Flags().String("since", "", "Start of the date range for counting on-call shifts (e.g. 2025-07-01, \"2025-07-15 17:00\", or 2025-07-15T17:00:00+09:00)")
 	countCmd.Flags().String("until", "", "End of the date range for counting on-call shifts")
-	countCmd.MarkFlagRequired("until")
+	countCmd.Flags().Bool("clip", false, "Count shifts partially overlapping the date range proportionally")
+	countCmd.Flags().String("period", "", "Period for counting on-call shifts instead of since and until (e.g. last-month, 2025-Q3, fiscal:2025, last-30-days)")
+	countCmd.Flags().Int("fiscal-year-start", 4, "Start month of fiscal years used by period")
+	countCmd.MarkFlagsOneRequired("since", "period")
+	countCmd.MarkFlagsOneRequired("until", "period")
+	countCmd.Flags().String("bucket", "", "Group shifts into buckets by the start time (week, month, or quarter)")
+	countCmd.Flags().BoolP("verbose", "v", false, "Show also excluded shifts in the details")
+	countCmd.Flags().Bool("fairness", false, "Show the fairness statistics of the counts")
+	countCmd.Flags().StringToString("fte", map[string]string{}, "FTE of part-time users used by fairness (e.g. \"Takeshi Arabiki=0.5\")")
+	countCmd.Flags().Bool("record", false, "Record the counts of the ended period in the ledger")
+}
+
+// newShiftGenerator returns a shift generator configured by v
+func newShiftGenerator(v *viper.Viper, tz *time.Location) (*pd.ShiftGenerator, error) {
+	since, until, err := getPeriod(v, tz)
+	if err != nil {
+		return nil, err
+	}
+
+	return newShiftGeneratorInPeriod(v, tz, since, until)
 }
 
-func runCount(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, since, until string, scheduleIDs []string, sg *pd.ShiftGenerator) error {
-	rsEntries := make(map[string][]pagerduty.RenderedScheduleEntry, len(scheduleIDs))
-	scheduleNames := make([]string, len(scheduleIDs))
-	iters := make([]*pd.ScheduleEntryIter, len(scheduleIDs))
+// newShiftGeneratorInPeriod returns a shift generator configured by v except for the period
+func newShiftGeneratorInPeriod(v *viper.Viper, tz *time.Location, since, until string) (*pd.ShiftGenerator, error) {
+	handoffTimes, effectiveHandoffTimes, err := getHandoffTimes(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dayClasses, err := getDayClasses(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return pd.NewShiftGenerator(
+		tz,
+		since,
+		until,
+		handoffTimes,
+		v.GetStringSlice("include"),
+		v.GetStringSlice("non-working-days"),
+		pd.WithDayTypeAnchor(v.GetString("day-type-anchor")),
+		pd.WithDayClasses(dayClasses),
+		pd.WithExclude(v.GetStringSlice("exclude")),
+		pd.WithSelect(v.GetString("select")),
+		pd.WithShiftLabels(v.GetStringMapString("shift-labels")),
+		pd.WithDayHandoffTimes(getDayHandoffTimes(v)),
+		pd.WithEffectiveHandoffTimes(effectiveHandoffTimes),
+		pd.WithClip(v.GetBool("clip")),
+		pd.WithHandoffCycle(v.GetInt("handoff-cycle-days"), v.GetString("handoff-cycle-anchor")),
+	)
+}
+
+// getPeriod returns since and until. If period is specified, it takes precedence and is resolved in tz.
+func getPeriod(v *viper.Viper, tz *time.Location) (string, string, error) {
+	period, err := getPeriodName(v)
+	if err != nil {
+		return "", "", err
+	}
+	if period != "" {
+		return pd.ParsePeriod(period, time.Now().In(tz), time.Month(v.GetInt("fiscal-year-start")))
+	}
+	return v.GetString("since"), v.GetString("until"), nil
+}
+
+// getPeriodName returns the period unless since or until is given by a flag, which takes precedence over
+// the period in config files and environment variables
+func getPeriodName(v *viper.Viper) (string, error) {
+	period := v.GetString("period")
+	if period == "" || !isSetByFlag(v, "since") && !isSetByFlag(v, "until") {
+		return period, nil
+	}
+	if isSetByFlag(v, "period") {
+		return "", errors.New("--period cannot be used with --since or --until")
+	}
+	return "", nil
+}
+
+func getDayClasses(v *viper.Viper) ([]pd.DayClass, error) {
+	var dayClasses []pd.DayClass
+	if err := v.UnmarshalKey("day-classes", &dayClasses); err != nil {
+		return nil, fmt.Errorf("invalid day-classes: %w", err)
+	}
+	return dayClasses, nil
+}
+
+// getHandoffTimes returns the handoff times and ones effective from specific dates.
+// handoff-times can be a list of entries with "from" and "times" in a config file.
+func getHandoffTimes(v *viper.Viper) ([]string, []pd.EffectiveHandoffTimes, error) {
+	if items, ok := v.Get("handoff-times").([]any); !ok || len(items) == 0 || !isMap(items[0]) {
+		handoffTimes := v.GetStringSlice("handoff-times")
+		slices.SortStableFunc(handoffTimes, pd.CompareHandoffTimes)
+		return handoffTimes, nil, nil
+	}
+
+	var entries []pd.EffectiveHandoffTimes
+	// YAML parses unquoted dates as timestamps
+	hook := viper.DecodeHook(func(_ reflect.Type, to reflect.Type, data any) (any, error) {
+		if t, ok := data.(time.Time); ok && to.Kind() == reflect.String {
+			return t.Format(time.DateOnly), nil
+		}
+		return data, nil
+	})
+	if err := v.UnmarshalKey("handoff-times", &entries, hook); err != nil {
+		return nil, nil, fmt.Errorf("invalid handoff-times: %w", err)
+	}
+	for _, e := range entries {
+		slices.SortStableFunc(e.Times, pd.CompareHandoffTimes)
+	}
+	slices.SortStableFunc(entries, func(a, b pd.EffectiveHandoffTimes) int {
+		return strings.Compare(a.From, b.From)
+	})
+
+	// The earliest handoff times are also used before their effective date
+	handoffTimes := entries[0].Times
+	if entries[0].From == "" {
+		entries = entries[1:]
+	}
+	if len(entries) > 0 && entries[0].From == "" {
+		return nil, nil, errors.New("invalid handoff-times: only one entry can omit from")
+	}
+	return handoffTimes, entries, nil
+}
+
+func isMap(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+func getDayHandoffTimes(v *viper.Viper) map[string][]string {
+	dayHandoffTimes := make(map[string][]string)
+	for day, times := range v.GetStringMapStringSlice("handoff-times-by-day") {
+		// Values from flags and environment variables are space-separated strings
+		times = strings.Fields(strings.Join(times, " "))
+		slices.SortStableFunc(times, pd.CompareHandoffTimes)
+		dayHandoffTimes[day] = times
+	}
+	return dayHandoffTimes
+}
+
+// getFTE returns the FTE of each user
+func getFTE(v *viper.Viper) (map[string]float64, error) {
+	fte := make(map[string]float64)
+	for user, value := range v.GetStringMapString("fte") {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("invalid FTE of %s: %q", user, value)
+		}
+		fte[user] = f
+	}
+	return fte, nil
+}
+
+func addCount(counts map[string]map[string]float64, user, column string, count float64) {
+	if counts[user] == nil {
+		counts[user] = make(map[string]float64)
+	}
+	counts[user][column] += count
+}
+
+// bucketOf returns the name of the bucket to which t belongs
+func bucketOf(t time.Time, bucket string) (string, error) {
+	switch bucket {
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case "month":
+		return t.Format("2006-01"), nil
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3), nil
+	default:
+		return "", fmt.Errorf("unknown bucket %q", bucket)
+	}
+}
+
+// printFairness prints the fairness statistics of the counts. Users in fte are matched case-insensitively.
+func printFairness(out io.Writer, users []string, counts map[string]float64, fte map[string]float64) {
+	userFTE := make(map[string]float64, len(fte))
+	for user, f := range fte {
+		if name, ok := findNameByKey(users, user); ok {
+			user = name
+		}
+		userFTE[user] = f
+	}
+	f := pd.NewFairness(counts, userFTE)
+
+	perFTE := ""
+	if len(fte) > 0 {
+		perFTE = " per FTE"
+	}
+	fmt.Fprintf(out, "# Fairness\n\n")
+	fmt.Fprintf(out, "- Mean%s: %0.2f\n", perFTE, f.Mean)
+	fmt.Fprintf(out, "- Standard deviation%s: %0.2f\n", perFTE, f.StdDev)
+	fmt.Fprintf(out, "- Min%s: %0.2f\n", perFTE, f.Min)
+	fmt.Fprintf(out, "- Max%s: %0.2f\n", perFTE, f.Max)
+	fmt.Fprintf(out, "- Gini coefficient: %0.2f\n\n", f.Gini)
+	fmt.Fprintf(out, "| User | FTE | Count | Target | Deviation |\n")
+	fmt.Fprintf(out, "|------|------|------|------|------|\n")
+	for _, uf := range f.Users {
+		fmt.Fprintf(out, "| %s | %0.2f | %0.2f | %0.2f | %+0.2f |\n", uf.User, uf.FTE, uf.Count, uf.Target, uf.Deviation)
+	}
+	fmt.Fprintln(out)
+}
+
+// findNameByKey returns the name corresponding to the key of a map setting. Keys are compared case-insensitively
+// because Viper lowercases keys in config files.
+func findNameByKey(names []string, key string) (string, bool) {
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// printSummaryTable prints counts for each user and column in a Markdown table.
+// If expected is not nil, the expected totals are also printed.
+func printSummaryTable(out io.Writer, users, columns []string, counts map[string]map[string]float64, expected map[string]float64) {
+	fmt.Fprintf(out, "| User | %s | Total |\n", strings.Join(columns, " | "))
+	fmt.Fprintf(out, "|------|%s-------|\n", strings.Repeat("------|", len(columns)))
+	columnTotals := make([]float64, len(columns))
+	total := 0.0
+	for _, user := range users {
+		fmt.Fprintf(out, "| %s |", user)
+		userTotal := 0.0
+		for i, column := range columns {
+			fmt.Fprintf(out, " %0.2f |", counts[user][column])
+			columnTotals[i] += counts[user][column]
+			userTotal += counts[user][column]
+		}
+		fmt.Fprintf(out, " %0.2f |\n", userTotal)
+		total += userTotal
+	}
+	fmt.Fprintf(out, "| Total |")
+	for _, t := range columnTotals {
+		fmt.Fprintf(out, " %0.2f |", t)
+	}
+	fmt.Fprintf(out, " %0.2f |\n", total)
+	if expected != nil {
+		fmt.Fprintf(out, "| Expected total |")
+		expectedTotal := 0.0
+		for _, column := range columns {
+			fmt.Fprintf(out, " %0.2f |", expected[column])
+			expectedTotal += expected[column]
+		}
+		fmt.Fprintf(out, " %0.2f |\n", expectedTotal)
+	}
+	fmt.Fprintln(out)
+}
+
+// inheritCountConfig makes v fall back to the values of the count subcommand for the given keys
+func inheritCountConfig(v *viper.Viper, keys ...string) {
+	for _, key := range keys {
+		if !v.IsSet(key) {
+			v.Set(key, vipers[countCmd].Get(key))
+		}
+	}
+}
+
+// getSchedules returns the schedules rendered in the period of sg
+func getSchedules(ctx context.Context, client pd.Client, tz *time.Location, scheduleIDs []string, sg *pd.ShiftGenerator) ([]*pagerduty.Schedule, error) {
+	// Align time to the handoff times to include entire shifts
+	start, end := sg.Period()
+
+	schedules := make([]*pagerduty.Schedule, len(scheduleIDs))
 	for i, id := range scheduleIDs {
-		schedule, err := client.GetScheduleWithContext(ctx, id, pagerduty.GetScheduleOptions{
-			TimeZone: tz.String(),
-			Since:    since,
-			Until:    until,
-		})
+		schedule, err := getSchedule(ctx, client, tz, id, start, end)
 		if err != nil {
-			var pdErr pagerduty.APIError
-			if errors.As(err, &pdErr) && pdErr.StatusCode == http.StatusUnauthorized {
-				return errors.New("failed to get PagerDuty schedule: unauthorized")
-			} else {
-				return fmt.Errorf("failed to get PagerDuty schedule: %w", err)
-			}
+			return nil, err
+		}
+		schedules[i] = schedule
+	}
+
+	return schedules, nil
+}
+
+// getSchedule returns the schedule rendered from since to until
+func getSchedule(ctx context.Context, client pd.Client, tz *time.Location, id string, since, until time.Time) (*pagerduty.Schedule, error) {
+	schedule, err := client.GetScheduleWithContext(ctx, id, pagerduty.GetScheduleOptions{
+		TimeZone: tz.String(),
+		Since:    since.Format(apiTimeLayout),
+		Until:    until.Format(apiTimeLayout),
+	})
+	if err != nil {
+		return nil, apiError("get PagerDuty schedule", err)
+	}
+	return schedule, nil
+}
+
+// apiError returns an error describing the failed action of the PagerDuty API
+func apiError(action string, err error) error {
+	var pdErr pagerduty.APIError
+	if errors.As(err, &pdErr) && pdErr.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("failed to %s: unauthorized", action)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// countOptions are the options of runCount changing what is shown or recorded
+type countOptions struct {
+	// verbose shows also excluded shifts in the details
+	verbose bool
+	// bucket is the unit of buckets grouping shifts, which is empty if shifts are not grouped
+	bucket   string
+	fairness bool
+	// fte is the FTE of part-time users used by fairness
+	fte map[string]float64
+	// ledgerPath is the path to the ledger file, which is empty if the counts are not recorded
+	ledgerPath string
+	ledgerKey  string
+}
+
+func runCount(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleIDs []string, sg *pd.ShiftGenerator, opts countOptions) error {
+	if opts.bucket != "" {
+		if _, err := bucketOf(time.Time{}, opts.bucket); err != nil {
+			return err
 		}
+	}
 
+	schedules, err := getSchedules(ctx, client, tz, scheduleIDs, sg)
+	if err != nil {
+		return err
+	}
+
+	rsEntries := make(map[string][]pagerduty.RenderedScheduleEntry, len(schedules))
+	scheduleNames := make([]string, len(schedules))
+	iters := make([]*pd.ScheduleEntryIter, len(schedules))
+	for i, schedule := range schedules {
 		rsEntries[schedule.Name] = schedule.FinalSchedule.RenderedScheduleEntries
 		scheduleNames[i] = schedule.Name
 		iters[i], err = pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
@@ -109,16 +449,73 @@ func runCount(ctx context.Context, out io.Writer, client pd.Client, tz *time.Loc
 
 	shifts := make([]*pd.Shift, 0)
 	shiftCounts := make(map[string]float64)
-	for shift := range sg.Shifts() {
+	classCounts := make(map[string]map[string]float64)
+	labelCounts := make(map[string]map[string]float64)
+	labelColumns := make(map[string]bool)
+	// Shifts without labels are labelled with their time ranges
+	labels := sg.ShiftLabels()
+	expectedTotal := 0.0
+	buckets := make([]string, 0)
+	bucketCounts := make(map[string]map[string]float64)
+	expectedBucketTotals := make(map[string]float64)
+	for shift := range sg.AllShifts() {
+		if shift.Weight == 0 {
+			if opts.verbose && shift.ExcludedBy != "" {
+				shifts = append(shifts, shift)
+			}
+			continue
+		}
 		for _, iter := range iters {
 			shift.AddDetails(iter)
 		}
 		shifts = append(shifts, shift)
+		expectedTotal += shift.Weight * shift.Fraction() * float64(len(iters))
 		for _, details := range shift.Details {
 			for _, detail := range details {
-				shiftCounts[detail.User] += detail.Proportion
+				shiftCounts[detail.User] += detail.Proportion * shift.Weight
+			}
+		}
+		if opts.bucket != "" {
+			b, _ := bucketOf(shift.Start, opts.bucket)
+			if !slices.Contains(buckets, b) {
+				buckets = append(buckets, b)
+			}
+			expectedBucketTotals[b] += shift.Weight * shift.Fraction() * float64(len(iters))
+			for _, details := range shift.Details {
+				for _, detail := range details {
+					addCount(bucketCounts, detail.User, b, detail.Proportion*shift.Weight)
+				}
+			}
+		}
+		label := sg.ShiftLabel(shift)
+		if labels != nil && !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+		for class, weight := range sg.DayClassWeights(shift) {
+			labelColumns[label+" / "+class] = true
+			for _, details := range shift.Details {
+				for _, detail := range details {
+					addCount(classCounts, detail.User, class, detail.Proportion*weight)
+					addCount(labelCounts, detail.User, label+" / "+class, detail.Proportion*weight)
+				}
+			}
+		}
+	}
+
+	if opts.ledgerPath != "" {
+		start, end := sg.Period()
+		// Record also the users without counted shifts so that they get negative carry-overs
+		ledgerCounts := maps.Clone(shiftCounts)
+		for _, entries := range rsEntries {
+			for _, entry := range entries {
+				if _, ok := ledgerCounts[entry.User.Summary]; !ok {
+					ledgerCounts[entry.User.Summary] = 0
+				}
 			}
 		}
+		if err := recordLedger(opts.ledgerPath, opts.ledgerKey, start, end, ledgerCounts, time.Now()); err != nil {
+			return err
+		}
 	}
 
 	fmt.Fprintf(out, "# Summary\n\n")
@@ -130,10 +527,56 @@ func runCount(ctx context.Context, out io.Writer, client pd.Client, tz *time.Loc
 		fmt.Fprintf(out, "- %s: %0.2f\n", user, shiftCounts[user])
 	}
 	fmt.Fprintf(out, "- Total: %0.2f\n", total)
-	fmt.Fprintf(out, "- Expected total: %v\n\n", len(shifts)*len(iters))
+	fmt.Fprintf(out, "- Expected total: %v\n\n", math.Round(expectedTotal*100)/100)
+	if classes := sg.DayClasses(); classes != nil {
+		fmt.Fprintf(out, "# Summary by day class\n\n")
+		printSummaryTable(out, users, classes, classCounts, nil)
+	}
+	if labels != nil {
+		classes := sg.DayClasses()
+		if classes == nil {
+			classes = []string{"working-days", "non-working-days"}
+		}
+		columns := make([]string, 0)
+		for _, label := range labels {
+			for _, class := range classes {
+				if labelColumns[label+" / "+class] {
+					columns = append(columns, label+" / "+class)
+				}
+			}
+		}
+		fmt.Fprintf(out, "# Summary by shift label\n\n")
+		printSummaryTable(out, users, columns, labelCounts, nil)
+	}
+	if opts.bucket != "" {
+		fmt.Fprintf(out, "# Summary by %s\n\n", opts.bucket)
+		printSummaryTable(out, users, buckets, bucketCounts, expectedBucketTotals)
+	}
+	if opts.fairness {
+		printFairness(out, users, shiftCounts, opts.fte)
+	}
 	fmt.Fprintf(out, "# Details\n\n")
 	for _, shift := range shifts {
-		fmt.Fprintf(out, "- %s - %s\n", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout))
+		if shift.Weight == 0 {
+			fmt.Fprintf(out, "- %s - %s (excluded by %q)\n", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout), shift.ExcludedBy)
+			continue
+		}
+		notes := make([]string, 0)
+		if shift.ExcludedBy != "" {
+			notes = append(notes, fmt.Sprintf("partly excluded by %q", shift.ExcludedBy))
+		}
+		if shift.Clipped() {
+			start, end := shift.CountedPeriod()
+			notes = append(notes, fmt.Sprintf("clipped to %s - %s, proportion: %0.2f", start.Format("01-02 15:04"), end.Format("01-02 15:04"), shift.Fraction()))
+		}
+		if shift.Weight != 1 {
+			notes = append(notes, fmt.Sprintf("weight: %0.2f", shift.Weight))
+		}
+		if len(notes) == 0 {
+			fmt.Fprintf(out, "- %s - %s\n", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout))
+		} else {
+			fmt.Fprintf(out, "- %s - %s (%s)\n", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout), strings.Join(notes, ", "))
+		}
 		for _, name := range scheduleNames {
 			fmt.Fprintf(out, "    - %s\n", name)
 			for _, detail := range shift.Details[name] {
@@ -3,12 +3,17 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/abicky/pd-shift/internal/pd"
 	"github.com/abicky/pd-shift/testing/mock"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"go.uber.org/mock/gomock"
 )
 
@@ -22,7 +27,11 @@ func Test_runCount(t *testing.T) {
 		include        []string
 		nonWorkingDays []string
 		scheduleIDs    []string
+		opts           []pd.ShiftGeneratorOption
+		options        countOptions
 		wantOutput     string
+		// wantLedger is the counts recorded in the ledger if not nil
+		wantLedger map[string]float64
 	}{
 		{
 			name:           "example",
@@ -77,6 +86,413 @@ func Test_runCount(t *testing.T) {
 
 ## Weekly Rotation
 
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "split day type anchor",
+			tz:             time.UTC,
+			since:          "2025-07-04",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("split")},
+			wantOutput: `# Summary
+
+- John Smith: 2.16
+- Takeshi Arabiki: 0.26
+- Total: 2.42
+- Expected total: 2.42
+
+# Details
+
+- Fri, 2025-07-04 17:00+0000 - Sat, 2025-07-05 05:00+0000 (weight: 0.42)
+    - Weekly Rotation
+        - John Smith: 0.58 (17:00 - 09:00)
+        - Takeshi Arabiki: 0.42 (09:00 - 05:00)
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.08 (05:00 - 15:00)
+        - John Smith: 0.92 (15:00 - 17:00)
+- Sat, 2025-07-05 17:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (17:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "day classes",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-08",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"holidays", "weekends:05:00-17:00"},
+			nonWorkingDays: []string{},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts: []pd.ShiftGeneratorOption{pd.WithDayClasses([]pd.DayClass{
+				{Name: "holidays", Days: []string{"Jul 7"}},
+				{Name: "weekends", Days: []string{"Sat", "Sun"}},
+			})},
+			wantOutput: `# Summary
+
+- John Smith: 1.92
+- Takeshi Arabiki: 1.33
+- Total: 3.25
+- Expected total: 4
+
+# Summary by day class
+
+| User | working-days | holidays | weekends | Total |
+|------|------|------|------|-------|
+| John Smith | 0.00 | 0.00 | 1.92 | 1.92 |
+| Takeshi Arabiki | 0.00 | 1.25 | 0.08 | 1.33 |
+| Total | 0.00 | 1.25 | 2.00 | 3.25 |
+
+# Details
+
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.08 (05:00 - 15:00)
+        - John Smith: 0.92 (15:00 - 17:00)
+- Sun, 2025-07-06 05:00+0000 - Sun, 2025-07-06 17:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (05:00 - 17:00)
+- Mon, 2025-07-07 05:00+0000 - Mon, 2025-07-07 17:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 1.00 (05:00 - 17:00)
+- Mon, 2025-07-07 17:00+0000 - Tue, 2025-07-08 05:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.25 (17:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "exclude with verbose",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-07",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithExclude([]string{"Sat:05:00-17:00", "2025-07-06:17:00-05:00"})},
+			options:        countOptions{verbose: true},
+			wantOutput: `# Summary
+
+- John Smith: 2.00
+- Total: 2.00
+- Expected total: 2
+
+# Details
+
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000 (excluded by "Sat:05:00-17:00")
+- Sat, 2025-07-05 17:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (17:00 - 05:00)
+- Sun, 2025-07-06 05:00+0000 - Sun, 2025-07-06 17:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (05:00 - 17:00)
+- Sun, 2025-07-06 17:00+0000 - Mon, 2025-07-07 05:00+0000 (excluded by "2025-07-06:17:00-05:00")
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "record",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithExclude([]string{"Sat:05:00-17:00"})},
+			options:        countOptions{ledgerKey: "P4DRALL"},
+			wantOutput: `# Summary
+
+- John Smith: 1.00
+- Total: 1.00
+- Expected total: 1
+
+# Details
+
+- Sat, 2025-07-05 17:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (17:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+			wantLedger: map[string]float64{"John Smith": 1, "Takeshi Arabiki": 0},
+		},
+		{
+			name:           "bucket",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-08",
+			handoffTimes:   []string{"05:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			scheduleIDs:    []string{"P4DRALL"},
+			options:        countOptions{bucket: "week"},
+			wantOutput: `# Summary
+
+- John Smith: 1.58
+- Takeshi Arabiki: 1.04
+- Total: 2.62
+- Expected total: 3
+
+# Summary by week
+
+| User | 2025-W27 | 2025-W28 | Total |
+|------|------|------|-------|
+| John Smith | 1.58 | 0.00 | 1.58 |
+| Takeshi Arabiki | 0.42 | 0.62 | 1.04 |
+| Total | 2.00 | 0.62 | 2.62 |
+| Expected total | 2.00 | 1.00 | 3.00 |
+
+# Details
+
+- Sat, 2025-07-05 05:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.04 (05:00 - 15:00)
+        - John Smith: 0.96 (15:00 - 05:00)
+- Sun, 2025-07-06 05:00+0000 - Mon, 2025-07-07 05:00+0000
+    - Weekly Rotation
+        - John Smith: 0.62 (05:00 - 05:00)
+        - Takeshi Arabiki: 0.38 (05:00 - 05:00)
+- Mon, 2025-07-07 05:00+0000 - Tue, 2025-07-08 05:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.62 (05:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "clip",
+			tz:             time.UTC,
+			since:          "2025-07-05 02:00",
+			until:          "2025-07-06 11:00",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithClip(true)},
+			wantOutput: `# Summary
+
+- John Smith: 2.42
+- Takeshi Arabiki: 0.33
+- Total: 2.75
+- Expected total: 2.75
+
+# Details
+
+- Fri, 2025-07-04 17:00+0000 - Sat, 2025-07-05 05:00+0000 (clipped to 07-05 02:00 - 07-05 05:00, proportion: 0.25)
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.25 (02:00 - 05:00)
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.08 (05:00 - 15:00)
+        - John Smith: 0.92 (15:00 - 17:00)
+- Sat, 2025-07-05 17:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (17:00 - 05:00)
+- Sun, 2025-07-06 05:00+0000 - Sun, 2025-07-06 17:00+0000 (clipped to 07-06 05:00 - 07-06 11:00, proportion: 0.50)
+    - Weekly Rotation
+        - John Smith: 0.50 (05:00 - 11:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "clip with split day type anchor",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-05 12:00",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithClip(true), pd.WithDayTypeAnchor("split")},
+			wantOutput: `# Summary
+
+- John Smith: 0.50
+- Takeshi Arabiki: 0.50
+- Total: 1.00
+- Expected total: 1
+
+# Details
+
+- Fri, 2025-07-04 17:00+0000 - Sat, 2025-07-05 05:00+0000 (clipped to 07-05 00:00 - 07-05 05:00, proportion: 0.42)
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.42 (00:00 - 05:00)
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000 (clipped to 07-05 05:00 - 07-05 12:00, proportion: 0.58)
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.08 (05:00 - 15:00)
+        - John Smith: 0.50 (15:00 - 12:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "shift labels",
+			tz:             time.UTC,
+			since:          "2025-07-04",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			scheduleIDs:    []string{"P4DRALL"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithShiftLabels(map[string]string{"17:00-05:00": "night"})},
+			wantOutput: `# Summary
+
+- John Smith: 3.50
+- Takeshi Arabiki: 0.50
+- Total: 4.00
+- Expected total: 4
+
+# Summary by shift label
+
+| User | night / working-days | night / non-working-days | 05:00-17:00 / working-days | 05:00-17:00 / non-working-days | Total |
+|------|------|------|------|------|-------|
+| John Smith | 0.58 | 1.00 | 1.00 | 0.92 | 3.50 |
+| Takeshi Arabiki | 0.42 | 0.00 | 0.00 | 0.08 | 0.50 |
+| Total | 1.00 | 1.00 | 1.00 | 1.00 | 4.00 |
+
+# Details
+
+- Fri, 2025-07-04 05:00+0000 - Fri, 2025-07-04 17:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (05:00 - 17:00)
+- Fri, 2025-07-04 17:00+0000 - Sat, 2025-07-05 05:00+0000
+    - Weekly Rotation
+        - John Smith: 0.58 (17:00 - 09:00)
+        - Takeshi Arabiki: 0.42 (09:00 - 05:00)
+- Sat, 2025-07-05 05:00+0000 - Sat, 2025-07-05 17:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.08 (05:00 - 15:00)
+        - John Smith: 0.92 (15:00 - 17:00)
+- Sat, 2025-07-05 17:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - John Smith: 1.00 (17:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
+- 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
+- 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
+- 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
+- 2025-07-07T05:00:00+09:00 - 2025-07-08T05:00:00+09:00: Takeshi Arabiki
+`,
+		},
+		{
+			name:           "fairness with FTE",
+			tz:             time.UTC,
+			since:          "2025-07-05",
+			until:          "2025-07-08",
+			handoffTimes:   []string{"05:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			scheduleIDs:    []string{"P4DRALL"},
+			options: countOptions{
+				fairness: true,
+				fte:      map[string]float64{"takeshi arabiki": 0.5, "Hanako Yamada": 0.5},
+			},
+			wantOutput: `# Summary
+
+- John Smith: 1.58
+- Takeshi Arabiki: 1.04
+- Total: 2.62
+- Expected total: 3
+
+# Fairness
+
+- Mean per FTE: 1.22
+- Standard deviation per FTE: 0.89
+- Min per FTE: 0.00
+- Max per FTE: 2.08
+- Gini coefficient: 0.38
+
+| User | FTE | Count | Target | Deviation |
+|------|------|------|------|------|
+| Hanako Yamada | 0.50 | 0.00 | 0.66 | -0.66 |
+| John Smith | 1.00 | 1.58 | 1.31 | +0.27 |
+| Takeshi Arabiki | 0.50 | 1.04 | 0.66 | +0.39 |
+
+# Details
+
+- Sat, 2025-07-05 05:00+0000 - Sun, 2025-07-06 05:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.04 (05:00 - 15:00)
+        - John Smith: 0.96 (15:00 - 05:00)
+- Sun, 2025-07-06 05:00+0000 - Mon, 2025-07-07 05:00+0000
+    - Weekly Rotation
+        - John Smith: 0.62 (05:00 - 05:00)
+        - Takeshi Arabiki: 0.38 (05:00 - 05:00)
+- Mon, 2025-07-07 05:00+0000 - Tue, 2025-07-08 05:00+0000
+    - Weekly Rotation
+        - Takeshi Arabiki: 0.62 (05:00 - 05:00)
+
+# PagerDuty schedules
+
+## Weekly Rotation
+
 - 2025-07-01T05:00:00+09:00 - 2025-07-05T09:00:00+09:00: John Smith
 - 2025-07-05T09:00:00+09:00 - 2025-07-05T15:00:00+09:00: Takeshi Arabiki
 - 2025-07-05T15:00:00+09:00 - 2025-07-07T05:00:00+09:00: John Smith
@@ -89,12 +505,18 @@ func Test_runCount(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
+			sg, err := pd.NewShiftGenerator(tt.tz, tt.since, tt.until, tt.handoffTimes, tt.include, tt.nonWorkingDays, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start, end := sg.Period()
 			client := mock.NewMockClient(ctrl)
 			for _, id := range tt.scheduleIDs {
 				client.EXPECT().GetScheduleWithContext(t.Context(), id, pagerduty.GetScheduleOptions{
 					TimeZone: tt.tz.String(),
-					Since:    tt.since + " " + tt.handoffTimes[0],
-					Until:    tt.until + " " + tt.handoffTimes[0],
+					Since:    start.Format("2006-01-02 15:04"),
+					Until:    end.Format("2006-01-02 15:04"),
 				}).DoAndReturn(func(_ context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error) {
 					return &pagerduty.Schedule{
 						Name: "Weekly Rotation",
@@ -134,19 +556,105 @@ func Test_runCount(t *testing.T) {
 				})
 			}
 
-			var b bytes.Buffer
-
-			sg, err := pd.NewShiftGenerator(tt.tz, tt.since, tt.until, tt.handoffTimes, tt.include, tt.nonWorkingDays)
-			if err != nil {
-				t.Fatal(err)
+			if tt.wantLedger != nil {
+				tt.options.ledgerPath = filepath.Join(t.TempDir(), "ledger.jsonl")
 			}
 
-			if err := runCount(t.Context(), &b, client, tt.tz, tt.since+" "+tt.handoffTimes[0], tt.until+" "+tt.handoffTimes[0], tt.scheduleIDs, sg); err != nil {
+			var b bytes.Buffer
+
+			if err := runCount(t.Context(), &b, client, tt.tz, tt.scheduleIDs, sg, tt.options); err != nil {
 				t.Errorf("runCount() = %v, want nil", err)
 			}
 			if b.String() != tt.wantOutput {
 				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
 			}
+
+			if tt.wantLedger != nil {
+				l, err := loadLedger(tt.options.ledgerPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				periods := l.Periods(tt.options.ledgerKey)
+				if len(periods) != 1 || !reflect.DeepEqual(periods[0].Counts, tt.wantLedger) {
+					t.Errorf("l.Periods() = %v, want counts %v", periods, tt.wantLedger)
+				}
+			}
+		})
+	}
+}
+
+func Test_getPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		args      []string
+		wantSince string
+		wantUntil string
+		wantErr   string
+	}{
+		{
+			name:      "period in config",
+			config:    "count:\n  period: 2025-Q3\n",
+			wantSince: "2025-07-01",
+			wantUntil: "2025-10-01",
+		},
+		{
+			name:      "period in config and since and until in flags",
+			config:    "count:\n  period: 2025-Q3\n",
+			args:      []string{"--since", "2025-08-01", "--until", "2025-08-15"},
+			wantSince: "2025-08-01",
+			wantUntil: "2025-08-15",
+		},
+		{
+			name:      "since and until in config and period in flags",
+			config:    "count:\n  since: 2025-08-01\n  until: 2025-08-15\n",
+			args:      []string{"--period", "2025-Q3"},
+			wantSince: "2025-07-01",
+			wantUntil: "2025-10-01",
+		},
+		{
+			name:    "period and since in flags",
+			config:  "count: {}\n",
+			args:    []string{"--period", "2025-Q3", "--since", "2025-08-01"},
+			wantErr: "--period cannot be used with --since or --until",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &cobra.Command{Use: "pd-shift"}
+			sub := &cobra.Command{Use: "count"}
+			sub.Flags().String("since", "", "")
+			sub.Flags().String("until", "", "")
+			sub.Flags().String("period", "", "")
+			sub.Flags().Int("fiscal-year-start", 4, "")
+			root.AddCommand(sub)
+			if err := sub.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			v := viper.New()
+			v.SetConfigType("yaml")
+			if err := v.ReadConfig(strings.NewReader(tt.config)); err != nil {
+				t.Fatal(err)
+			}
+			if err := bindPFlags(v, root); err != nil {
+				t.Fatal(err)
+			}
+			defer delete(vipers, sub)
+
+			since, until, err := getPeriod(vipers[sub], time.UTC)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("getPeriod() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getPeriod() = %v, want nil", err)
+			}
+			if since != tt.wantSince || until != tt.wantUntil {
+				t.Errorf("getPeriod() = %s, %s, want %s, %s", since, until, tt.wantSince, tt.wantUntil)
+			}
 		})
 	}
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecast on-call shift counts at the end of the period",
+	Long: `This command counts shifts ended by now and shifts already rendered up to the horizon,
+and projects each user's count at the end of the period by distributing the expected count of the rest
+in proportion to the counts so far. Users whose projected counts are out of the tolerance of the target are highlighted.
+Shifts are generated by the configuration of the count subcommand.`,
+	Args:    cobra.NoArgs,
+	GroupID: defaultCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, shiftGeneratorKeys...)
+		inheritCountConfig(v, "schedule-ids")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		sg, err := newShiftGenerator(v, tz)
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		now := time.Now().In(tz)
+		return runForecast(
+			cmd.Context(),
+			os.Stdout,
+			client,
+			tz,
+			v.GetStringSlice("schedule-ids"),
+			sg,
+			now,
+			now.AddDate(0, 0, v.GetInt("horizon-days")),
+			v.GetFloat64("target"),
+			v.GetFloat64("tolerance"),
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+
+	forecastCmd.Flags().StringSlice("schedule-ids", []string{}, "List of scheduled IDs to forecast (default count.schedule-ids)")
+	forecastCmd.Flags().String("since", "", "Start of the date range to forecast")
+	forecastCmd.Flags().String("until", "", "End of the date range to forecast")
+	forecastCmd.Flags().String("period", "", "Period to forecast instead of since and until (e.g. fiscal:2025, 2025-H2)")
+	forecastCmd.MarkFlagsOneRequired("since", "period")
+	forecastCmd.MarkFlagsOneRequired("until", "period")
+	forecastCmd.Flags().Int("horizon-days", 30, "Number of days from now during which the rendered schedules are counted")
+	forecastCmd.Flags().Float64("target", 0, "Target count of each user (default the mean of the projected counts)")
+	forecastCmd.Flags().Float64("tolerance", 1, "Allowed difference between the projected count and the target")
+}
+
+func runForecast(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleIDs []string, sg *pd.ShiftGenerator, now, horizon time.Time, target, tolerance float64) error {
+	shifts := make([]*pd.Shift, 0)
+	renderedEnd := time.Time{}
+	for shift := range sg.AllShifts() {
+		if shift.Weight == 0 {
+			continue
+		}
+		shifts = append(shifts, shift)
+		if shift.Start.Before(horizon) {
+			renderedEnd = shift.End
+		}
+	}
+
+	if !renderedEnd.IsZero() {
+		start, _ := sg.Period()
+		for _, id := range scheduleIDs {
+			schedule, err := getSchedule(ctx, client, tz, id, start, renderedEnd)
+			if err != nil {
+				return err
+			}
+			iter, err := pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
+			if err != nil {
+				return err
+			}
+			for _, shift := range shifts {
+				if !shift.Start.Before(horizon) {
+					break
+				}
+				shift.AddDetails(iter)
+			}
+		}
+	}
+
+	forecast := pd.NewForecast(shifts, now, horizon, len(scheduleIDs))
+	if target == 0 && len(forecast.Entries) > 0 {
+		for _, e := range forecast.Entries {
+			target += e.Projected
+		}
+		target /= float64(len(forecast.Entries))
+	}
+
+	fmt.Fprintf(out, "# Forecast\n\n")
+	fmt.Fprintf(out, "| User | Actual | Scheduled | Projected | Target | Status |\n")
+	fmt.Fprintf(out, "|------|------|------|------|------|------|\n")
+	for _, e := range forecast.Entries {
+		status := "ok"
+		if e.Projected > target+tolerance {
+			status = "**over**"
+		} else if e.Projected < target-tolerance {
+			status = "**under**"
+		}
+		fmt.Fprintf(out, "| %s | %0.2f | %0.2f | %0.2f | %0.2f | %s |\n", e.User, e.Actual, e.Scheduled, e.Projected, target, status)
+	}
+	fmt.Fprintln(out)
+	if !renderedEnd.IsZero() {
+		fmt.Fprintf(out, "- Rendered until: %s\n", renderedEnd.Format(dateTimeLayout))
+	}
+	fmt.Fprintf(out, "- Remaining after the horizon: %0.2f\n", forecast.Remaining)
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/abicky/pd-shift/testing/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_runForecast(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		target     float64
+		wantOutput string
+	}{
+		{
+			name: "Mean target",
+			wantOutput: `# Forecast
+
+| User | Actual | Scheduled | Projected | Target | Status |
+|------|------|------|------|------|------|
+| John Smith | 2.00 | 5.50 | 15.00 | 9.00 | **over** |
+| Takeshi Arabiki | 0.00 | 1.50 | 3.00 | 9.00 | **under** |
+
+- Rendered until: Tue, 2025-07-08 05:00+0900
+- Remaining after the horizon: 9.00
+`,
+		},
+		{
+			name:   "Explicit target",
+			target: 14,
+			wantOutput: `# Forecast
+
+| User | Actual | Scheduled | Projected | Target | Status |
+|------|------|------|------|------|------|
+| John Smith | 2.00 | 5.50 | 15.00 | 14.00 | ok |
+| Takeshi Arabiki | 0.00 | 1.50 | 3.00 | 14.00 | **under** |
+
+- Rendered until: Tue, 2025-07-08 05:00+0900
+- Remaining after the horizon: 9.00
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sg, err := pd.NewShiftGenerator(jst, "2025-07-01", "2025-07-15", []string{"05:00", "17:00"}, []string{"working-days:17:00-05:00", "non-working-days"}, []string{"JP holidays", "Sat", "Sun"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := mock.NewMockClient(ctrl)
+			client.EXPECT().GetScheduleWithContext(t.Context(), "P4DRALL", pagerduty.GetScheduleOptions{
+				TimeZone: jst.String(),
+				Since:    "2025-07-01 05:00",
+				Until:    "2025-07-08 05:00",
+			}).Return(&pagerduty.Schedule{
+				Name: "Weekly Rotation",
+				FinalSchedule: pagerduty.ScheduleLayer{
+					RenderedScheduleEntries: []pagerduty.RenderedScheduleEntry{
+						{
+							Start: "2025-07-01T05:00:00+09:00",
+							End:   "2025-07-05T09:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-05T09:00:00+09:00",
+							End:   "2025-07-05T15:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "Takeshi Arabiki"},
+						},
+						{
+							Start: "2025-07-05T15:00:00+09:00",
+							End:   "2025-07-07T05:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-07T05:00:00+09:00",
+							End:   "2025-07-08T05:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "Takeshi Arabiki"},
+						},
+					},
+				},
+			}, nil)
+
+			var b bytes.Buffer
+
+			now := time.Date(2025, time.July, 4, 0, 0, 0, 0, jst)
+			horizon := time.Date(2025, time.July, 8, 0, 0, 0, 0, jst)
+			if err := runForecast(t.Context(), &b, client, jst, []string{"P4DRALL"}, sg, now, horizon, tt.target, 1); err != nil {
+				t.Errorf("runForecast() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+var holidaysCmd = &cobra.Command{
+	Use:   "holidays",
+	Short: "Show how each day is classified by the non-working days",
+	Long: `This command shows whether each day in the date range is a working day or a non-working day,
+together with the rules that matched. If non-working-days is not specified, count.non-working-days is used.
+Day classes are read from count.day-classes unless holidays.day-classes is set.`,
+	Args:    cobra.NoArgs,
+	GroupID: auxiliaryCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "non-working-days", "day-classes")
+
+		dayClasses, err := getDayClasses(v)
+		if err != nil {
+			return err
+		}
+
+		return runHolidays(
+			os.Stdout,
+			v.GetString("since"),
+			v.GetString("until"),
+			v.GetStringSlice("non-working-days"),
+			dayClasses,
+			v.GetString("output"),
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(holidaysCmd)
+
+	holidaysCmd.Flags().StringSlice("non-working-days", []string{}, "List of non-working days (default count.non-working-days)")
+	holidaysCmd.Flags().String("since", "", "Start of the date range")
+	holidaysCmd.MarkFlagRequired("since")
+	holidaysCmd.Flags().String("until", "", "End of the date range")
+	holidaysCmd.MarkFlagRequired("until")
+	holidaysCmd.Flags().StringP("output", "o", "text", "Output format (text or json)")
+}
+
+type holidaysJSONEntry struct {
+	Date         string   `json:"date"`
+	Weekday      string   `json:"weekday"`
+	Class        string   `json:"class"`
+	NonWorking   bool     `json:"non_working"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+func runHolidays(out io.Writer, since, until string, nonWorkingDays []string, dayClasses []pd.DayClass, output string) error {
+	days, err := pd.ClassifyDays(since, until, nonWorkingDays, dayClasses)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "text":
+		for _, day := range days {
+			switch {
+			case !day.NonWorking:
+				fmt.Fprintf(out, "- %s: working day\n", day.Date.Format("Mon, 2006-01-02"))
+			case len(dayClasses) == 0:
+				fmt.Fprintf(out, "- %s: non-working day (%s)\n", day.Date.Format("Mon, 2006-01-02"), strings.Join(day.MatchedRules, ", "))
+			default:
+				fmt.Fprintf(out, "- %s: %s (%s)\n", day.Date.Format("Mon, 2006-01-02"), day.Class, strings.Join(day.MatchedRules, ", "))
+			}
+		}
+	case "json":
+		entries := make([]holidaysJSONEntry, len(days))
+		for i, day := range days {
+			entries[i] = holidaysJSONEntry{
+				Date:         day.Date.Format(time.DateOnly),
+				Weekday:      day.Date.Weekday().String(),
+				Class:        day.Class,
+				NonWorking:   day.NonWorking,
+				MatchedRules: day.MatchedRules,
+			}
+		}
+		return encodeJSON(out, entries)
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+
+	return nil
+}
+
+func encodeJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func Test_runHolidays(t *testing.T) {
+	tests := []struct {
+		name           string
+		since          string
+		until          string
+		nonWorkingDays []string
+		dayClasses     []pd.DayClass
+		output         string
+		wantOutput     string
+	}{
+		{
+			name:           "text",
+			since:          "2025-07-18",
+			until:          "2025-07-22",
+			nonWorkingDays: []string{"JP holidays", "Sat", "Sun"},
+			output:         "text",
+			wantOutput: `- Fri, 2025-07-18: working day
+- Sat, 2025-07-19: non-working day (Sat)
+- Sun, 2025-07-20: non-working day (Sun)
+- Mon, 2025-07-21: non-working day (JP holidays (Marine Day))
+`,
+		},
+		{
+			name:           "json",
+			since:          "2025-07-20",
+			until:          "2025-07-22",
+			nonWorkingDays: []string{"JP holidays", "Sat", "Sun", "Jul 21"},
+			output:         "json",
+			wantOutput: `[
+  {
+    "date": "2025-07-20",
+    "weekday": "Sunday",
+    "class": "non-working-days",
+    "non_working": true,
+    "matched_rules": [
+      "Sun"
+    ]
+  },
+  {
+    "date": "2025-07-21",
+    "weekday": "Monday",
+    "class": "non-working-days",
+    "non_working": true,
+    "matched_rules": [
+      "JP holidays (Marine Day)",
+      "Jul 21"
+    ]
+  }
+]
+`,
+		},
+		{
+			name:           "day classes",
+			since:          "2025-07-18",
+			until:          "2025-07-22",
+			nonWorkingDays: []string{},
+			dayClasses: []pd.DayClass{
+				{Name: "holidays", Days: []string{"JP holidays"}},
+				{Name: "weekends", Days: []string{"Sat", "Sun"}},
+			},
+			output: "text",
+			wantOutput: `- Fri, 2025-07-18: working day
+- Sat, 2025-07-19: weekends (Sat)
+- Sun, 2025-07-20: weekends (Sun)
+- Mon, 2025-07-21: holidays (JP holidays (Marine Day))
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			if err := runHolidays(&b, tt.since, tt.until, tt.nonWorkingDays, tt.dayClasses, tt.output); err != nil {
+				t.Errorf("runHolidays() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ledgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Show cumulative balances recorded in the ledger",
+	Long: `This command shows the cumulative counts recorded by "pd-shift count --record" and manual adjustments,
+together with the carry-over of each user, which is the count the user has done more than expected.
+The expected count of each period is the mean of the counts of the users recorded in the period.
+Counts are kept per profile, or per set of count.schedule-ids if no profile is specified.`,
+	Args:    cobra.NoArgs,
+	GroupID: auxiliaryCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "time-zone", "schedule-ids")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		path, err := ledgerFile()
+		if err != nil {
+			return err
+		}
+
+		return runLedger(os.Stdout, tz, path, ledgerKey(v))
+	},
+}
+
+var ledgerAdjustCmd = &cobra.Command{
+	Use:   "adjust <user> <count>",
+	Short: "Adjust the count of a user in the ledger",
+	Long: `This command records a manual adjustment of the count of a user with a reason.
+A positive count is regarded as extra work and a negative count as work to be cancelled.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "schedule-ids")
+
+		count, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid count: %q", args[1])
+		}
+
+		path, err := ledgerFile()
+		if err != nil {
+			return err
+		}
+
+		return runAdjustLedger(os.Stdout, path, ledgerKey(v), args[0], count, v.GetString("reason"), time.Now())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ledgerCmd)
+	ledgerCmd.AddCommand(ledgerAdjustCmd)
+
+	ledgerCmd.Flags().String("time-zone", "", "Time zone used to show the periods (default count.time-zone)")
+
+	ledgerAdjustCmd.Flags().String("reason", "", "Reason of the adjustment")
+	ledgerAdjustCmd.MarkFlagRequired("reason")
+}
+
+// ledgerFile returns the path to the ledger file, which is under the data directory
+// according to https://specifications.freedesktop.org/basedir-spec/0.8/ by default
+func ledgerFile() (string, error) {
+	if path := viper.GetString("ledger-file"); path != "" {
+		return path, nil
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, toolName, "ledger.jsonl"), nil
+}
+
+// ledgerKey returns the key of the counts in the ledger, which is the lowercased profile name if specified
+// in the same way as profileSettings, or the sorted schedule IDs otherwise
+func ledgerKey(v *viper.Viper) string {
+	if profile := viper.GetString("profile"); profile != "" {
+		return strings.ToLower(profile)
+	}
+	return strings.Join(slices.Sorted(slices.Values(v.GetStringSlice("schedule-ids"))), ",")
+}
+
+// loadLedger reads the ledger file, which is regarded as empty if it does not exist
+func loadLedger(path string) (*pd.Ledger, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &pd.Ledger{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	l, err := pd.ReadLedger(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// saveLedger writes the ledger to a temporary file and renames it so as not to corrupt the ledger file
+func saveLedger(path string, l *pd.Ledger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := l.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// recordLedger records the counts of the period for the key in the ledger file
+func recordLedger(path, key string, since, until time.Time, counts map[string]float64, now time.Time) error {
+	l, err := loadLedger(path)
+	if err != nil {
+		return err
+	}
+	if err := l.Record(key, since, until, counts, now); err != nil {
+		return err
+	}
+	return saveLedger(path, l)
+}
+
+func runLedger(out io.Writer, tz *time.Location, path, key string) error {
+	l, err := loadLedger(path)
+	if err != nil {
+		return err
+	}
+	periods := l.Periods(key)
+	adjustments := l.Adjustments(key)
+	if len(periods) == 0 && len(adjustments) == 0 {
+		fmt.Fprintf(out, "No counts for %q are recorded in %s.\n", key, path)
+		return nil
+	}
+
+	fmt.Fprintf(out, "# Balances\n\n")
+	fmt.Fprintf(out, "| User | Counted | Adjusted | Expected | Carry-over |\n")
+	fmt.Fprintf(out, "|------|------|------|------|------|\n")
+	for _, b := range l.Balances(key) {
+		fmt.Fprintf(out, "| %s | %0.2f | %0.2f | %0.2f | %+0.2f |\n", b.User, b.Counted, b.Adjusted, b.Expected, b.CarryOver)
+	}
+
+	if len(periods) > 0 {
+		fmt.Fprintf(out, "\n# Periods\n\n")
+		for _, p := range periods {
+			counts := make([]string, 0, len(p.Counts))
+			for _, user := range slices.Sorted(maps.Keys(p.Counts)) {
+				counts = append(counts, fmt.Sprintf("%s: %0.2f", user, p.Counts[user]))
+			}
+			fmt.Fprintf(out, "- %s - %s: %s\n", p.Since.In(tz).Format(dateTimeLayout), p.Until.In(tz).Format(dateTimeLayout), strings.Join(counts, ", "))
+		}
+	}
+
+	if len(adjustments) > 0 {
+		fmt.Fprintf(out, "\n# Adjustments\n\n")
+		for _, e := range adjustments {
+			fmt.Fprintf(out, "- %s: %s %+0.2f (%s)\n", e.RecordedAt.In(tz).Format(dateTimeLayout), e.User, e.Count, e.Reason)
+		}
+	}
+
+	return nil
+}
+
+func runAdjustLedger(out io.Writer, path, key, user string, count float64, reason string, now time.Time) error {
+	l, err := loadLedger(path)
+	if err != nil {
+		return err
+	}
+	if err := l.Adjust(key, user, count, reason, now); err != nil {
+		return err
+	}
+	if err := saveLedger(path, l); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Adjusted the count of %s by %+0.2f.\n", user, count)
+	return nil
+}
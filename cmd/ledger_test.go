@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func Test_runLedger(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		ledger     string
+		wantOutput string
+	}{
+		{
+			name: "Counts and adjustments",
+			ledger: `{"type":"count","key":"P4DRALL","since":"2025-04-01T00:00:00+09:00","until":"2025-07-01T00:00:00+09:00","user":"Alice","count":6,"recorded_at":"2025-07-02T00:00:00+09:00"}
+{"type":"count","key":"P4DRALL","since":"2025-04-01T00:00:00+09:00","until":"2025-07-01T00:00:00+09:00","user":"Bob","count":3,"recorded_at":"2025-07-02T00:00:00+09:00"}
+{"type":"adjustment","key":"P4DRALL","user":"Bob","count":1,"reason":"Handled an incident off schedule","recorded_at":"2025-08-01T10:00:00+09:00"}
+{"type":"count","key":"P4DRALL","since":"2025-07-01T00:00:00+09:00","until":"2025-10-01T00:00:00+09:00","user":"Alice","count":2,"recorded_at":"2025-10-02T00:00:00+09:00"}
+{"type":"count","key":"P4DRALL","since":"2025-07-01T00:00:00+09:00","until":"2025-10-01T00:00:00+09:00","user":"Bob","count":4,"recorded_at":"2025-10-02T00:00:00+09:00"}
+{"type":"count","key":"P4DRALL","since":"2025-07-01T00:00:00+09:00","until":"2025-10-01T00:00:00+09:00","user":"Carol","count":3,"recorded_at":"2025-10-02T00:00:00+09:00"}
+{"type":"count","key":"PXXXXXX","since":"2025-07-01T00:00:00+09:00","until":"2025-10-01T00:00:00+09:00","user":"Dave","count":5,"recorded_at":"2025-10-02T00:00:00+09:00"}
+`,
+			wantOutput: `# Balances
+
+| User | Counted | Adjusted | Expected | Carry-over |
+|------|------|------|------|------|
+| Alice | 8.00 | 0.00 | 7.50 | +0.50 |
+| Bob | 7.00 | 1.00 | 7.50 | +0.50 |
+| Carol | 3.00 | 0.00 | 3.00 | +0.00 |
+
+# Periods
+
+- Tue, 2025-04-01 00:00+0900 - Tue, 2025-07-01 00:00+0900: Alice: 6.00, Bob: 3.00
+- Tue, 2025-07-01 00:00+0900 - Wed, 2025-10-01 00:00+0900: Alice: 2.00, Bob: 4.00, Carol: 3.00
+
+# Adjustments
+
+- Fri, 2025-08-01 10:00+0900: Bob +1.00 (Handled an incident off schedule)
+`,
+		},
+		{
+			name:       "No ledger",
+			wantOutput: "No counts for \"P4DRALL\" are recorded in LEDGER.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ledger.jsonl")
+			if tt.ledger != "" {
+				if err := os.WriteFile(path, []byte(tt.ledger), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var b bytes.Buffer
+			if err := runLedger(&b, jst, path, "P4DRALL"); err != nil {
+				t.Errorf("runLedger() = %v, want nil", err)
+			}
+			if want := bytes.ReplaceAll([]byte(tt.wantOutput), []byte("LEDGER"), []byte(path)); b.String() != string(want) {
+				t.Errorf("b.String() = %v, want %v", b.String(), string(want))
+			}
+		})
+	}
+}
+
+func Test_runAdjustLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pd-shift", "ledger.jsonl")
+	since := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, time.July, 2, 0, 0, 0, 0, time.UTC)
+
+	if err := recordLedger(path, "P4DRALL", since, until, map[string]float64{"Alice": 6}, now); err != nil {
+		t.Fatalf("recordLedger() = %v, want nil", err)
+	}
+
+	var b bytes.Buffer
+	if err := runAdjustLedger(&b, path, "P4DRALL", "Alice", -1.5, "Swapped a shift off the record", now); err != nil {
+		t.Fatalf("runAdjustLedger() = %v, want nil", err)
+	}
+	if want := "Adjusted the count of Alice by -1.50.\n"; b.String() != want {
+		t.Errorf("b.String() = %v, want %v", b.String(), want)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"count","key":"P4DRALL","since":"2025-04-01T00:00:00Z","until":"2025-07-01T00:00:00Z","user":"Alice","count":6,"recorded_at":"2025-07-02T00:00:00Z"}
+{"type":"adjustment","key":"P4DRALL","user":"Alice","count":-1.5,"reason":"Swapped a shift off the record","recorded_at":"2025-07-02T00:00:00Z"}
+`
+	if string(got) != want {
+		t.Errorf("ledger = %v, want %v", string(got), want)
+	}
+
+	if err := runAdjustLedger(&b, path, "P4DRALL", "Alice", 1, "", now); err == nil || err.Error() != "reason of the adjustment is required" {
+		t.Errorf("runAdjustLedger() = %v, want an error", err)
+	}
+}
+
+func Test_ledgerKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		scheduleIDs []string
+		want        string
+	}{
+		{
+			name:        "Profile",
+			profile:     "SRE",
+			scheduleIDs: []string{"P4DRALL"},
+			want:        "sre",
+		},
+		{
+			name:        "Schedule IDs",
+			scheduleIDs: []string{"PWEEKLY", "P4DRALL"},
+			want:        "P4DRALL,PWEEKLY",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := viper.GetString("profile")
+			viper.Set("profile", tt.profile)
+			t.Cleanup(func() { viper.Set("profile", profile) })
+
+			v := viper.New()
+			v.Set("schedule-ids", tt.scheduleIDs)
+			if got := ledgerKey(v); got != tt.want {
+				t.Errorf("ledgerKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
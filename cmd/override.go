@@ -0,0 +1,440 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var overrideCmd = &cobra.Command{
+	Use:     "override",
+	Short:   "Manage PagerDuty schedule overrides",
+	Long:    "This command creates, lists, and deletes overrides of a PagerDuty schedule.",
+	Args:    cobra.NoArgs,
+	GroupID: defaultCommandGroup.ID,
+}
+
+var overrideCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create PagerDuty schedule overrides",
+	Long: `This command validates overrides against the rendered schedule and shows the changes they make.
+Overrides are created only if --apply is specified. Overrides can be read from a YAML, JSON, or CSV file
+such as the output of "pd-shift plan --output overrides".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "time-zone")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		var overrides []*pd.Override
+		if path := v.GetString("file"); path != "" {
+			overrides, err = loadOverrides(path, tz, v.GetString("schedule-id"))
+		} else {
+			overrides, err = newOverrides(v.GetString("start"), v.GetString("end"), v.GetString("user-id"), tz)
+		}
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runCreateOverrides(cmd.Context(), os.Stdout, client, tz, v.GetString("schedule-id"), overrides, v.GetBool("apply"))
+	},
+}
+
+var overrideListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List PagerDuty schedule overrides",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "time-zone")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+		since, err := pd.ParseTime(v.GetString("since"), tz)
+		if err != nil {
+			return err
+		}
+		until, err := pd.ParseTime(v.GetString("until"), tz)
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runListOverrides(cmd.Context(), os.Stdout, client, tz, v.GetString("schedule-id"), since, until)
+	},
+}
+
+var overrideDeleteCmd = &cobra.Command{
+	Use:   "delete <override ID>...",
+	Short: "Delete PagerDuty schedule overrides",
+	Long: `This command deletes overrides of a PagerDuty schedule only if --apply is specified.
+Otherwise, it shows the overrides to be deleted, which are looked up between --since and --until.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, "time-zone")
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+		since := time.Now().In(tz)
+		if v.GetString("since") != "" {
+			since, err = pd.ParseTime(v.GetString("since"), tz)
+			if err != nil {
+				return err
+			}
+		}
+		until := since.AddDate(1, 0, 0)
+		if v.GetString("until") != "" {
+			until, err = pd.ParseTime(v.GetString("until"), tz)
+			if err != nil {
+				return err
+			}
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runDeleteOverrides(cmd.Context(), os.Stdout, client, tz, v.GetString("schedule-id"), args, since, until, v.GetBool("apply"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(overrideCmd)
+	overrideCmd.AddCommand(overrideCreateCmd, overrideListCmd, overrideDeleteCmd)
+
+	overrideCreateCmd.Flags().String("schedule-id", "", "ID of the schedule to override")
+	overrideCreateCmd.MarkFlagRequired("schedule-id")
+	overrideCreateCmd.Flags().String("time-zone", "", "Time zone used for start and end (default count.time-zone)")
+	overrideCreateCmd.Flags().String("user-id", "", "ID of the user to be on call")
+	overrideCreateCmd.Flags().String("start", "", "Start of the override (e.g. \"2025-07-15 17:00\" or 2025-07-15T17:00:00+09:00)")
+	overrideCreateCmd.Flags().String("end", "", "End of the override")
+	overrideCreateCmd.Flags().StringP("file", "f", "", "Path to a YAML, JSON, or CSV file of overrides")
+	overrideCreateCmd.MarkFlagsRequiredTogether("user-id", "start", "end")
+	overrideCreateCmd.MarkFlagsOneRequired("user-id", "file")
+	overrideCreateCmd.MarkFlagsMutuallyExclusive("user-id", "file")
+	overrideCreateCmd.Flags().Bool("apply", false, "Create the overrides instead of showing the changes")
+
+	overrideListCmd.Flags().String("schedule-id", "", "ID of the schedule")
+	overrideListCmd.MarkFlagRequired("schedule-id")
+	overrideListCmd.Flags().String("time-zone", "", "Time zone used for since and until (default count.time-zone)")
+	overrideListCmd.Flags().String("since", "", "Start of the date range")
+	overrideListCmd.MarkFlagRequired("since")
+	overrideListCmd.Flags().String("until", "", "End of the date range")
+	overrideListCmd.MarkFlagRequired("until")
+
+	overrideDeleteCmd.Flags().String("schedule-id", "", "ID of the schedule")
+	overrideDeleteCmd.MarkFlagRequired("schedule-id")
+	overrideDeleteCmd.Flags().String("time-zone", "", "Time zone used for since, until, and the output (default count.time-zone)")
+	overrideDeleteCmd.Flags().String("since", "", "Start of the date range to look up the overrides in a dry run (default: now)")
+	overrideDeleteCmd.Flags().String("until", "", "End of the date range to look up the overrides in a dry run (default: a year after since)")
+	overrideDeleteCmd.Flags().Bool("apply", false, "Delete the overrides instead of showing them")
+}
+
+// overrideFileEntry is an override in a file, where the user ID is specified by either user_id or user.id
+type overrideFileEntry struct {
+	ScheduleID string `yaml:"schedule_id"`
+	Start      string `yaml:"start"`
+	End        string `yaml:"end"`
+	UserID     string `yaml:"user_id"`
+	User       struct {
+		ID      string `yaml:"id"`
+		Summary string `yaml:"summary"`
+	} `yaml:"user"`
+}
+
+func newOverrides(start, end, userID string, tz *time.Location) ([]*pd.Override, error) {
+	o, err := newOverride(overrideFileEntry{Start: start, End: end, UserID: userID}, tz)
+	if err != nil {
+		return nil, err
+	}
+	return []*pd.Override{o}, nil
+}
+
+func newOverride(e overrideFileEntry, tz *time.Location) (*pd.Override, error) {
+	start, err := pd.ParseTime(e.Start, tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of the override: %w", err)
+	}
+	end, err := pd.ParseTime(e.End, tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of the override: %w", err)
+	}
+	userID := e.UserID
+	if userID == "" {
+		userID = e.User.ID
+	}
+	return &pd.Override{Start: start, End: end, UserID: userID, User: e.User.Summary}, nil
+}
+
+// loadOverrides reads overrides of the schedule from a CSV file with the header "start,end,user_id"
+// or a YAML or JSON file with the "overrides" key
+func loadOverrides(path string, tz *time.Location, scheduleID string) ([]*pd.Override, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []overrideFileEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err := csv.NewReader(f).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(records) == 0 {
+			return nil, fmt.Errorf("no header in %s", path)
+		}
+		columns := make(map[string]int)
+		for _, name := range []string{"start", "end", "user_id"} {
+			i := slices.Index(records[0], name)
+			if i < 0 {
+				return nil, fmt.Errorf("missing column %q in %s", name, path)
+			}
+			columns[name] = i
+		}
+		for _, record := range records[1:] {
+			entries = append(entries, overrideFileEntry{
+				Start:  record[columns["start"]],
+				End:    record[columns["end"]],
+				UserID: record[columns["user_id"]],
+			})
+		}
+	} else {
+		var content struct {
+			Overrides []overrideFileEntry `yaml:"overrides"`
+		}
+		if err := yaml.NewDecoder(f).Decode(&content); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		entries = content.Overrides
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no overrides in %s", path)
+	}
+	overrides := make([]*pd.Override, len(entries))
+	for i, e := range entries {
+		if e.ScheduleID != "" && e.ScheduleID != scheduleID {
+			return nil, fmt.Errorf("override from %s to %s in %s is for the schedule %s, not %s", e.Start, e.End, path, e.ScheduleID, scheduleID)
+		}
+		overrides[i], err = newOverride(e, tz)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return overrides, nil
+}
+
+func runCreateOverrides(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleID string, overrides []*pd.Override, apply bool) error {
+	since := slices.MinFunc(overrides, func(a, b *pd.Override) int { return a.Start.Compare(b.Start) }).Start
+	until := slices.MaxFunc(overrides, func(a, b *pd.Override) int { return a.End.Compare(b.End) }).End
+	schedule, err := getSchedule(ctx, client, tz, scheduleID, since, until)
+	if err != nil {
+		return err
+	}
+
+	userNames := make(map[string]string)
+	for _, entry := range schedule.FinalSchedule.RenderedScheduleEntries {
+		userNames[entry.User.ID] = entry.User.Summary
+	}
+	for _, o := range overrides {
+		if o.User == "" {
+			o.User = userNames[o.UserID]
+		}
+		if o.User == "" {
+			o.User = o.UserID
+		}
+	}
+
+	iter, err := pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
+	if err != nil {
+		return err
+	}
+	diffs, err := pd.DiffOverrides(overrides, iter)
+	if err != nil {
+		return err
+	}
+
+	printOverrideDiffs(out, tz, schedule.Name, diffs)
+
+	if !apply {
+		fmt.Fprintf(out, "\nThis is a dry run. Specify --apply to create %d overrides.\n", len(diffs))
+		return nil
+	}
+
+	fmt.Fprintf(out, "\n# Created overrides\n\n")
+	created := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		o, err := createOverride(ctx, client, scheduleID, diff.Override)
+		if err != nil {
+			if len(created) > 0 {
+				return fmt.Errorf("%w (created %s; %d of %d overrides are not created)", err, strings.Join(created, ", "), len(diffs)-len(created), len(diffs))
+			}
+			return err
+		}
+		created = append(created, o.ID)
+		if err := printOverride(out, tz, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runListOverrides(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleID string, since, until time.Time) error {
+	resp, err := client.ListOverridesWithContext(ctx, scheduleID, pagerduty.ListOverridesOptions{
+		Since: since.Format(time.RFC3339),
+		Until: until.Format(time.RFC3339),
+	})
+	if err != nil {
+		return apiError("list PagerDuty overrides", err)
+	}
+
+	if len(resp.Overrides) == 0 {
+		fmt.Fprintf(out, "No overrides found.\n")
+	}
+	for _, o := range resp.Overrides {
+		if err := printOverride(out, tz, &o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runDeleteOverrides(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleID string, overrideIDs []string, since, until time.Time, apply bool) error {
+	if !apply {
+		if err := printDeletedOverrides(ctx, out, client, tz, scheduleID, overrideIDs, since, until); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\nThis is a dry run. Specify --apply to delete %d overrides.\n", len(overrideIDs))
+		return nil
+	}
+
+	for _, id := range overrideIDs {
+		if err := client.DeleteOverrideWithContext(ctx, scheduleID, id); err != nil {
+			return apiError("delete PagerDuty override", err)
+		}
+		fmt.Fprintf(out, "Deleted override %s\n", id)
+	}
+
+	return nil
+}
+
+// printDeletedOverrides shows the overrides to be deleted in the same format as printOverrideDiffs
+func printDeletedOverrides(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleID string, overrideIDs []string, since, until time.Time) error {
+	resp, err := client.ListOverridesWithContext(ctx, scheduleID, pagerduty.ListOverridesOptions{
+		Since: since.Format(time.RFC3339),
+		Until: until.Format(time.RFC3339),
+	})
+	if err != nil {
+		return apiError("list PagerDuty overrides", err)
+	}
+
+	type override struct {
+		id         string
+		user       string
+		start, end time.Time
+	}
+	overrides := make([]*override, len(overrideIDs))
+	for i, id := range overrideIDs {
+		j := slices.IndexFunc(resp.Overrides, func(o pagerduty.Override) bool { return o.ID == id })
+		if j == -1 {
+			return fmt.Errorf("override %s is not found from %s to %s", id, since.Format(dateTimeLayout), until.Format(dateTimeLayout))
+		}
+		start, err := time.Parse(time.RFC3339, resp.Overrides[j].Start)
+		if err != nil {
+			return err
+		}
+		end, err := time.Parse(time.RFC3339, resp.Overrides[j].End)
+		if err != nil {
+			return err
+		}
+		overrides[i] = &override{id: id, user: resp.Overrides[j].User.Summary, start: start.In(tz), end: end.In(tz)}
+	}
+	slices.SortStableFunc(overrides, func(a, b *override) int { return a.start.Compare(b.start) })
+
+	end := slices.MaxFunc(overrides, func(a, b *override) int { return a.end.Compare(b.end) }).end
+	schedule, err := getSchedule(ctx, client, tz, scheduleID, overrides[0].start, end)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "# %s\n\n", schedule.Name)
+	for _, o := range overrides {
+		fmt.Fprintf(out, "- %s - %s (%s)\n", o.start.Format(dateTimeLayout), o.end.Format(dateTimeLayout), o.id)
+		fmt.Fprintf(out, "    - %s (%s - %s)\n", o.user, o.start.Format("15:04"), o.end.Format("15:04"))
+	}
+	return nil
+}
+
+func printOverride(out io.Writer, tz *time.Location, o *pagerduty.Override) error {
+	start, err := time.Parse(time.RFC3339, o.Start)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339, o.End)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "- %s - %s: %s (%s)\n", start.In(tz).Format(dateTimeLayout), end.In(tz).Format(dateTimeLayout), o.User.Summary, o.ID)
+	return nil
+}
+
+func printOverrideDiffs(out io.Writer, tz *time.Location, scheduleName string, diffs []*pd.OverrideDiff) {
+	fmt.Fprintf(out, "# %s\n\n", scheduleName)
+	for _, diff := range diffs {
+		fmt.Fprintf(out, "- %s - %s\n", diff.Override.Start.In(tz).Format(dateTimeLayout), diff.Override.End.In(tz).Format(dateTimeLayout))
+		for _, detail := range diff.Replaced {
+			fmt.Fprintf(out, "    - %s (%s - %s)\n", detail.User, detail.Start.Format("15:04"), detail.End.Format("15:04"))
+		}
+		fmt.Fprintf(out, "    + %s\n", diff.Override.User)
+	}
+}
+
+func createOverride(ctx context.Context, client pd.Client, scheduleID string, o *pd.Override) (*pagerduty.Override, error) {
+	created, err := client.CreateOverrideWithContext(ctx, scheduleID, pagerduty.Override{
+		Start: o.Start.Format(time.RFC3339),
+		End:   o.End.Format(time.RFC3339),
+		User: pagerduty.APIObject{
+			ID:   o.UserID,
+			Type: "user_reference",
+		},
+	})
+	if err != nil {
+		return nil, apiError("create PagerDuty override", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,400 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/abicky/pd-shift/testing/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_loadOverrides(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*pd.Override{
+		{
+			Start:  time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+			End:    time.Date(2025, time.July, 6, 5, 0, 0, 0, jst),
+			UserID: "PTAKESH",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []*pd.Override
+		wantErr string
+	}{
+		{
+			name: "YAML",
+			file: "overrides.yaml",
+			content: `overrides:
+  - start: 2025-07-05 17:00
+    end: 2025-07-06T05:00:00+09:00
+    user_id: PTAKESH
+`,
+			want: want,
+		},
+		{
+			name: "JSON output by plan",
+			file: "overrides.json",
+			content: `{
+  "overrides": [
+    {
+      "schedule_id": "P4DRALL",
+      "start": "2025-07-05T08:00:00Z",
+      "end": "2025-07-05T20:00:00Z",
+      "user": {
+        "id": "PTAKESH",
+        "type": "user_reference",
+        "summary": "Takeshi Arabiki"
+      }
+    }
+  ]
+}
+`,
+			want: []*pd.Override{
+				{
+					Start:  time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+					End:    time.Date(2025, time.July, 6, 5, 0, 0, 0, jst),
+					UserID: "PTAKESH",
+					User:   "Takeshi Arabiki",
+				},
+			},
+		},
+		{
+			name: "CSV",
+			file: "overrides.csv",
+			content: `user_id,start,end
+PTAKESH,2025-07-05 17:00,2025-07-06 05:00
+`,
+			want: want,
+		},
+		{
+			name: "Another schedule",
+			file: "overrides.yaml",
+			content: `overrides:
+  - schedule_id: P5DBALL
+    start: 2025-07-05 17:00
+    end: 2025-07-06 05:00
+    user_id: PTAKESH
+`,
+			wantErr: "override from 2025-07-05 17:00 to 2025-07-06 05:00 in PATH is for the schedule P5DBALL, not P4DRALL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := loadOverrides(path, jst, "P4DRALL")
+			if tt.wantErr != "" {
+				if want := strings.ReplaceAll(tt.wantErr, "PATH", path); err == nil || err.Error() != want {
+					t.Errorf("loadOverrides() = %v, want %s", err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadOverrides() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runCreateOverrides(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		apply bool
+		// failAt is the 1-based index of the override failing to be created
+		failAt     int
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:  "Dry run",
+			apply: false,
+			wantOutput: `# Weekly Rotation
+
+- Sat, 2025-07-05 05:00+0900 - Sat, 2025-07-05 17:00+0900
+    - John Smith (05:00 - 09:00)
+    - Takeshi Arabiki (09:00 - 15:00)
+    - John Smith (15:00 - 17:00)
+    + Takeshi Arabiki
+- Sun, 2025-07-06 05:00+0900 - Sun, 2025-07-06 17:00+0900
+    - John Smith (05:00 - 17:00)
+    + Takeshi Arabiki
+
+This is a dry run. Specify --apply to create 2 overrides.
+`,
+		},
+		{
+			name:    "Partial failure",
+			apply:   true,
+			failAt:  2,
+			wantErr: "failed to create PagerDuty override: server error (created PO1; 1 of 2 overrides are not created)",
+		},
+		{
+			name:  "Apply",
+			apply: true,
+			wantOutput: `# Weekly Rotation
+
+- Sat, 2025-07-05 05:00+0900 - Sat, 2025-07-05 17:00+0900
+    - John Smith (05:00 - 09:00)
+    - Takeshi Arabiki (09:00 - 15:00)
+    - John Smith (15:00 - 17:00)
+    + Takeshi Arabiki
+- Sun, 2025-07-06 05:00+0900 - Sun, 2025-07-06 17:00+0900
+    - John Smith (05:00 - 17:00)
+    + Takeshi Arabiki
+
+# Created overrides
+
+- Sat, 2025-07-05 05:00+0900 - Sat, 2025-07-05 17:00+0900: Takeshi Arabiki (PO1)
+- Sun, 2025-07-06 05:00+0900 - Sun, 2025-07-06 17:00+0900: Takeshi Arabiki (PO2)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			overrides := []*pd.Override{
+				{
+					Start:  time.Date(2025, time.July, 6, 5, 0, 0, 0, jst),
+					End:    time.Date(2025, time.July, 6, 17, 0, 0, 0, jst),
+					UserID: "PTAKESH",
+				},
+				{
+					Start:  time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+					End:    time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+					UserID: "PTAKESH",
+				},
+			}
+
+			client := mock.NewMockClient(ctrl)
+			client.EXPECT().GetScheduleWithContext(t.Context(), "P4DRALL", pagerduty.GetScheduleOptions{
+				TimeZone: jst.String(),
+				Since:    "2025-07-05 05:00",
+				Until:    "2025-07-06 17:00",
+			}).Return(&pagerduty.Schedule{
+				Name: "Weekly Rotation",
+				FinalSchedule: pagerduty.ScheduleLayer{
+					RenderedScheduleEntries: []pagerduty.RenderedScheduleEntry{
+						{
+							Start: "2025-07-05T05:00:00+09:00",
+							End:   "2025-07-05T09:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PJOHN12", Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-05T09:00:00+09:00",
+							End:   "2025-07-05T15:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+						},
+						{
+							Start: "2025-07-05T15:00:00+09:00",
+							End:   "2025-07-06T17:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PJOHN12", Summary: "John Smith"},
+						},
+					},
+				},
+			}, nil)
+			if tt.apply {
+				for i, o := range []*pd.Override{overrides[1], overrides[0]} {
+					call := client.EXPECT().CreateOverrideWithContext(t.Context(), "P4DRALL", pagerduty.Override{
+						Start: o.Start.Format(time.RFC3339),
+						End:   o.End.Format(time.RFC3339),
+						User:  pagerduty.APIObject{ID: "PTAKESH", Type: "user_reference"},
+					})
+					if i+1 == tt.failAt {
+						call.Return(nil, errors.New("server error"))
+						break
+					}
+					call.Return(&pagerduty.Override{
+						ID:    []string{"PO1", "PO2"}[i],
+						Start: o.Start.Format(time.RFC3339),
+						End:   o.End.Format(time.RFC3339),
+						User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+					}, nil)
+				}
+			}
+
+			var b bytes.Buffer
+
+			err := runCreateOverrides(t.Context(), &b, client, jst, "P4DRALL", overrides, tt.apply)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("runCreateOverrides() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("runCreateOverrides() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_runListOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := mock.NewMockClient(ctrl)
+	client.EXPECT().ListOverridesWithContext(t.Context(), "P4DRALL", pagerduty.ListOverridesOptions{
+		Since: "2025-07-01T00:00:00+09:00",
+		Until: "2025-08-01T00:00:00+09:00",
+	}).Return(&pagerduty.ListOverridesResponse{
+		Overrides: []pagerduty.Override{
+			{
+				ID:    "PO1",
+				Start: "2025-07-05T05:00:00+09:00",
+				End:   "2025-07-05T17:00:00+09:00",
+				User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+			},
+		},
+	}, nil)
+
+	var b bytes.Buffer
+
+	since := time.Date(2025, time.July, 1, 0, 0, 0, 0, jst)
+	until := time.Date(2025, time.August, 1, 0, 0, 0, 0, jst)
+	if err := runListOverrides(t.Context(), &b, client, jst, "P4DRALL", since, until); err != nil {
+		t.Errorf("runListOverrides() = %v, want nil", err)
+	}
+	want := "- Sat, 2025-07-05 05:00+0900 - Sat, 2025-07-05 17:00+0900: Takeshi Arabiki (PO1)\n"
+	if b.String() != want {
+		t.Errorf("b.String() = %v, want %v", b.String(), want)
+	}
+}
+
+func Test_runDeleteOverrides(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2025, time.July, 1, 0, 0, 0, 0, jst)
+	until := time.Date(2025, time.August, 1, 0, 0, 0, 0, jst)
+
+	tests := []struct {
+		name        string
+		overrideIDs []string
+		apply       bool
+		wantOutput  string
+		wantErr     string
+	}{
+		{
+			name:        "Dry run",
+			overrideIDs: []string{"PO2", "PO1"},
+			apply:       false,
+			wantOutput: `# Weekly Rotation
+
+- Sat, 2025-07-05 05:00+0900 - Sat, 2025-07-05 17:00+0900 (PO1)
+    - Takeshi Arabiki (05:00 - 17:00)
+- Sun, 2025-07-06 05:00+0900 - Sun, 2025-07-06 17:00+0900 (PO2)
+    - Takeshi Arabiki (05:00 - 17:00)
+
+This is a dry run. Specify --apply to delete 2 overrides.
+`,
+		},
+		{
+			name:        "Dry run with unknown override",
+			overrideIDs: []string{"PO1", "PO3"},
+			apply:       false,
+			wantErr:     "override PO3 is not found from Tue, 2025-07-01 00:00+0900 to Fri, 2025-08-01 00:00+0900",
+		},
+		{
+			name:        "Apply",
+			overrideIDs: []string{"PO1", "PO2"},
+			apply:       true,
+			wantOutput: `Deleted override PO1
+Deleted override PO2
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock.NewMockClient(ctrl)
+			if tt.apply {
+				for _, id := range tt.overrideIDs {
+					client.EXPECT().DeleteOverrideWithContext(t.Context(), "P4DRALL", id).Return(nil)
+				}
+			} else {
+				client.EXPECT().ListOverridesWithContext(t.Context(), "P4DRALL", pagerduty.ListOverridesOptions{
+					Since: "2025-07-01T00:00:00+09:00",
+					Until: "2025-08-01T00:00:00+09:00",
+				}).Return(&pagerduty.ListOverridesResponse{
+					Overrides: []pagerduty.Override{
+						{
+							ID:    "PO1",
+							Start: "2025-07-05T05:00:00+09:00",
+							End:   "2025-07-05T17:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+						},
+						{
+							ID:    "PO2",
+							Start: "2025-07-06T05:00:00+09:00",
+							End:   "2025-07-06T17:00:00+09:00",
+							User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+						},
+					},
+				}, nil)
+				if tt.wantErr == "" {
+					client.EXPECT().GetScheduleWithContext(t.Context(), "P4DRALL", pagerduty.GetScheduleOptions{
+						TimeZone: jst.String(),
+						Since:    "2025-07-05 05:00",
+						Until:    "2025-07-06 17:00",
+					}).Return(&pagerduty.Schedule{Name: "Weekly Rotation"}, nil)
+				}
+			}
+
+			var b bytes.Buffer
+
+			err := runDeleteOverrides(t.Context(), &b, client, jst, "P4DRALL", tt.overrideIDs, since, until, tt.apply)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("runDeleteOverrides() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("runDeleteOverrides() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+)
+
+// shiftGeneratorKeys are the keys of the count subcommand used to generate shifts
+var shiftGeneratorKeys = []string{
+	"time-zone",
+	"handoff-times",
+	"handoff-times-by-day",
+	"handoff-cycle-days",
+	"handoff-cycle-anchor",
+	"include",
+	"select",
+	"exclude",
+	"non-working-days",
+	"day-classes",
+	"day-type-anchor",
+	"shift-labels",
+	"fiscal-year-start",
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan a fair on-call schedule",
+	Long: `This command assigns future shifts to participants so that the weighted shift counts are balanced.
+Shifts are generated by the configuration of the count subcommand, and shifts not to be counted are also
+assigned to participants. The result can be output as a table, JSON, or PagerDuty overrides.`,
+	Args:    cobra.NoArgs,
+	GroupID: auxiliaryCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, shiftGeneratorKeys...)
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		sg, err := newShiftGenerator(v, tz)
+		if err != nil {
+			return err
+		}
+
+		participants, err := parseParticipants(v.GetStringSlice("participants"), v.GetStringSlice("unavailable"))
+		if err != nil {
+			return err
+		}
+
+		return runPlan(
+			os.Stdout,
+			sg,
+			participants,
+			pd.PlanConstraints{
+				MaxConsecutiveNights: v.GetInt("max-consecutive-nights"),
+				MinRest:              time.Duration(v.GetFloat64("min-rest-hours") * float64(time.Hour)),
+			},
+			v.GetString("output"),
+			v.GetString("schedule-id"),
+			time.Now(),
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringSlice("participants", []string{}, "List of participants in the format <name> or <name>=<PagerDuty user ID>")
+	planCmd.MarkFlagRequired("participants")
+	planCmd.Flags().String("since", "", "Start of the date range to plan")
+	planCmd.Flags().String("until", "", "End of the date range to plan")
+	planCmd.Flags().String("period", "", "Period to plan instead of since and until (e.g. this-month, 2025-Q4)")
+	planCmd.MarkFlagsOneRequired("since", "period")
+	planCmd.MarkFlagsOneRequired("until", "period")
+	planCmd.Flags().Int("max-consecutive-nights", 0, "Maximum number of consecutive night shifts spanning midnight (0 means no limit)")
+	planCmd.Flags().Float64("min-rest-hours", 0, "Minimum rest hours between shifts of the same participant")
+	planCmd.Flags().StringSlice("unavailable", []string{}, "List of unavailable dates in the format <name>:<date> or <name>:<start date>/<end date>")
+	planCmd.Flags().StringP("output", "o", "table", "Output format (table, json, or overrides)")
+	planCmd.Flags().String("schedule-id", "", "ID of the schedule to override, which is required for the overrides output")
+}
+
+// parseParticipants parses participants in the format "<name>" or "<name>=<user ID>"
+// and unavailable dates in the format "<name>:<date>" or "<name>:<start date>/<end date>"
+func parseParticipants(values, unavailable []string) ([]*pd.Participant, error) {
+	participants := make([]*pd.Participant, len(values))
+	names := make(map[string]bool, len(values))
+	for i, value := range values {
+		name, userID, _ := strings.Cut(value, "=")
+		if names[name] {
+			return nil, fmt.Errorf("duplicate participant %q", name)
+		}
+		participants[i] = &pd.Participant{Name: name, UserID: userID}
+		names[name] = true
+	}
+
+	if err := addUnavailableDates(participants, unavailable); err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+// addUnavailableDates adds unavailable dates in the format "<name>:<date>" or "<name>:<start date>/<end date>"
+// to participants
+func addUnavailableDates(participants []*pd.Participant, unavailable []string) error {
+	byName := make(map[string]*pd.Participant, len(participants))
+	for _, p := range participants {
+		byName[p.Name] = p
+	}
+
+	for _, value := range unavailable {
+		i := strings.LastIndex(value, ":")
+		if i < 0 {
+			return fmt.Errorf("invalid unavailable value %q", value)
+		}
+		p, ok := byName[value[:i]]
+		if !ok {
+			return fmt.Errorf("unknown participant %q in unavailable value %q", value[:i], value)
+		}
+		start, end, _ := strings.Cut(value[i+1:], "/")
+		if end == "" {
+			end = start
+		}
+		startDate, err := time.Parse(time.DateOnly, start)
+		if err != nil {
+			return fmt.Errorf("invalid unavailable value %q: %w", value, err)
+		}
+		endDate, err := time.Parse(time.DateOnly, end)
+		if err != nil {
+			return fmt.Errorf("invalid unavailable value %q: %w", value, err)
+		}
+		for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+			p.UnavailableDates = append(p.UnavailableDates, d.Format(time.DateOnly))
+		}
+	}
+
+	return nil
+}
+
+type planJSONEntry struct {
+	Start       string  `json:"start"`
+	End         string  `json:"end"`
+	Participant string  `json:"participant"`
+	UserID      string  `json:"user_id,omitempty"`
+	Weight      float64 `json:"weight"`
+}
+
+// planOverride is an override output by plan together with the ID of the schedule to override
+type planOverride struct {
+	ScheduleID string `json:"schedule_id"`
+	pagerduty.Override
+}
+
+func runPlan(out io.Writer, sg *pd.ShiftGenerator, participants []*pd.Participant, constraints pd.PlanConstraints, output, scheduleID string, now time.Time) error {
+	if !slices.Contains([]string{"table", "json", "overrides"}, output) {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	if output == "overrides" && scheduleID == "" {
+		return errors.New("schedule-id is required to output overrides")
+	}
+
+	assignments, err := pd.Plan(sg.AllShifts(), participants, constraints)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "table":
+		shiftCounts := make(map[string]int)
+		weightedCounts := make(map[string]float64)
+		for _, a := range assignments {
+			shiftCounts[a.Participant.Name]++
+			weightedCounts[a.Participant.Name] += a.Weight
+		}
+		fmt.Fprintf(out, "# Summary\n\n")
+		fmt.Fprintf(out, "| Participant | Shifts | Weighted shifts |\n")
+		fmt.Fprintf(out, "|------|------|------|\n")
+		for _, p := range participants {
+			fmt.Fprintf(out, "| %s | %d | %0.2f |\n", p.Name, shiftCounts[p.Name], weightedCounts[p.Name])
+		}
+		fmt.Fprintf(out, "\n# Assignments\n\n")
+		fmt.Fprintf(out, "| Start | End | Participant | Weight |\n")
+		fmt.Fprintf(out, "|------|------|------|------|\n")
+		for _, a := range assignments {
+			fmt.Fprintf(out, "| %s | %s | %s | %0.2f |\n", a.Shift.Start.Format(dateTimeLayout), a.Shift.End.Format(dateTimeLayout), a.Participant.Name, a.Weight)
+		}
+	case "json":
+		entries := make([]planJSONEntry, len(assignments))
+		for i, a := range assignments {
+			entries[i] = planJSONEntry{
+				Start:       a.Shift.Start.Format(time.RFC3339),
+				End:         a.Shift.End.Format(time.RFC3339),
+				Participant: a.Participant.Name,
+				UserID:      a.Participant.UserID,
+				Weight:      a.Weight,
+			}
+		}
+		return encodeJSON(out, entries)
+	case "overrides":
+		overrides := make([]planOverride, len(assignments))
+		for i, a := range assignments {
+			if a.Participant.UserID == "" {
+				return fmt.Errorf("PagerDuty user ID of %s is required to output overrides", a.Participant.Name)
+			}
+			if a.Shift.Start.Before(now) {
+				return fmt.Errorf("shift from %s to %s starts in the past, so overrides cannot be created; specify since after now",
+					a.Shift.Start.Format(dateTimeLayout), a.Shift.End.Format(dateTimeLayout))
+			}
+			overrides[i] = planOverride{
+				ScheduleID: scheduleID,
+				Override: pagerduty.Override{
+					Start: a.Shift.Start.Format(time.RFC3339),
+					End:   a.Shift.End.Format(time.RFC3339),
+					User: pagerduty.APIObject{
+						ID:      a.Participant.UserID,
+						Type:    "user_reference",
+						Summary: a.Participant.Name,
+					},
+				},
+			}
+		}
+		return encodeJSON(out, map[string][]planOverride{"overrides": overrides})
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func Test_runPlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		participants []string
+		unavailable  []string
+		constraints  pd.PlanConstraints
+		output       string
+		now          time.Time
+		wantOutput   string
+		wantErr      string
+	}{
+		{
+			name:         "table",
+			participants: []string{"Alice", "Bob", "Carol"},
+			unavailable:  []string{"Carol:2025-08-01/2025-08-02"},
+			constraints:  pd.PlanConstraints{MinRest: 12 * time.Hour},
+			output:       "table",
+			wantOutput: `# Summary
+
+| Participant | Shifts | Weighted shifts |
+|------|------|------|
+| Alice | 2 | 1.00 |
+| Bob | 2 | 2.00 |
+| Carol | 0 | 0.00 |
+
+# Assignments
+
+| Start | End | Participant | Weight |
+|------|------|------|------|
+| Fri, 2025-08-01 05:00+0000 | Fri, 2025-08-01 17:00+0000 | Alice | 0.00 |
+| Fri, 2025-08-01 17:00+0000 | Sat, 2025-08-02 05:00+0000 | Bob | 1.00 |
+| Sat, 2025-08-02 05:00+0000 | Sat, 2025-08-02 17:00+0000 | Alice | 1.00 |
+| Sat, 2025-08-02 17:00+0000 | Sun, 2025-08-03 05:00+0000 | Bob | 1.00 |
+`,
+		},
+		{
+			name:         "json",
+			participants: []string{"Alice=PALICE1", "Bob"},
+			output:       "json",
+			wantOutput: `[
+  {
+    "start": "2025-08-01T05:00:00Z",
+    "end": "2025-08-01T17:00:00Z",
+    "participant": "Alice",
+    "user_id": "PALICE1",
+    "weight": 0
+  },
+  {
+    "start": "2025-08-01T17:00:00Z",
+    "end": "2025-08-02T05:00:00Z",
+    "participant": "Bob",
+    "weight": 1
+  },
+  {
+    "start": "2025-08-02T05:00:00Z",
+    "end": "2025-08-02T17:00:00Z",
+    "participant": "Alice",
+    "user_id": "PALICE1",
+    "weight": 1
+  },
+  {
+    "start": "2025-08-02T17:00:00Z",
+    "end": "2025-08-03T05:00:00Z",
+    "participant": "Bob",
+    "weight": 1
+  }
+]
+`,
+		},
+		{
+			name:         "overrides",
+			participants: []string{"Alice=PALICE1", "Bob=PBOB123"},
+			output:       "overrides",
+			now:          time.Date(2025, time.July, 31, 0, 0, 0, 0, time.UTC),
+			wantOutput: `{
+  "overrides": [
+    {
+      "schedule_id": "P4DRALL",
+      "start": "2025-08-01T05:00:00Z",
+      "end": "2025-08-01T17:00:00Z",
+      "user": {
+        "id": "PALICE1",
+        "type": "user_reference",
+        "summary": "Alice"
+      }
+    },
+    {
+      "schedule_id": "P4DRALL",
+      "start": "2025-08-01T17:00:00Z",
+      "end": "2025-08-02T05:00:00Z",
+      "user": {
+        "id": "PBOB123",
+        "type": "user_reference",
+        "summary": "Bob"
+      }
+    },
+    {
+      "schedule_id": "P4DRALL",
+      "start": "2025-08-02T05:00:00Z",
+      "end": "2025-08-02T17:00:00Z",
+      "user": {
+        "id": "PALICE1",
+        "type": "user_reference",
+        "summary": "Alice"
+      }
+    },
+    {
+      "schedule_id": "P4DRALL",
+      "start": "2025-08-02T17:00:00Z",
+      "end": "2025-08-03T05:00:00Z",
+      "user": {
+        "id": "PBOB123",
+        "type": "user_reference",
+        "summary": "Bob"
+      }
+    }
+  ]
+}
+`,
+		},
+		{
+			name:         "overrides starting in the past",
+			participants: []string{"Alice=PALICE1", "Bob=PBOB123"},
+			output:       "overrides",
+			now:          time.Date(2025, time.August, 1, 12, 0, 0, 0, time.UTC),
+			wantErr:      "shift from Fri, 2025-08-01 05:00+0000 to Fri, 2025-08-01 17:00+0000 starts in the past, so overrides cannot be created; specify since after now",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			sg, err := pd.NewShiftGenerator(time.UTC, "2025-08-01", "2025-08-03", []string{"05:00", "17:00"}, []string{"working-days:17:00-05:00", "non-working-days"}, []string{"Sat", "Sun"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			participants, err := parseParticipants(tt.participants, tt.unavailable)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = runPlan(&b, sg, participants, tt.constraints, tt.output, "P4DRALL", tt.now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("runPlan() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("runPlan() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
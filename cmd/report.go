@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Count PagerDuty on-call shifts for multiple definitions",
+	Long: `This command counts on-call shifts for each count definition in report.definitions and shows
+a section per definition followed by the org-wide roll-up. Each definition has a name and the configuration
+of the count subcommand such as schedule-ids, handoff-times, include, and non-working-days, which falls back
+to the configuration of the count subcommand. Schedules are fetched once even if they are used by multiple definitions.`,
+	Args:    cobra.NoArgs,
+	GroupID: defaultCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+
+		definitions, err := newReportDefinitions(v)
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runReport(cmd.Context(), os.Stdout, client, definitions)
+	},
+}
+
+// reportDefinitionKeys are the keys of the count subcommand that can be specified in each report definition
+var reportDefinitionKeys = append(slices.Clone(shiftGeneratorKeys), "schedule-ids", "clip")
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().String("since", "", "Start of the date range for counting on-call shifts")
+	reportCmd.Flags().String("until", "", "End of the date range for counting on-call shifts")
+	reportCmd.Flags().String("period", "", "Period for counting on-call shifts instead of since and until (e.g. last-month, 2025-Q3)")
+	reportCmd.MarkFlagsOneRequired("since", "period")
+	reportCmd.MarkFlagsOneRequired("until", "period")
+}
+
+type reportDefinition struct {
+	name        string
+	tz          *time.Location
+	scheduleIDs []string
+	sg          *pd.ShiftGenerator
+}
+
+// newReportDefinitions returns the count definitions in report.definitions.
+// The period of the report is shared by all the definitions.
+func newReportDefinitions(v *viper.Viper) ([]*reportDefinition, error) {
+	items, ok := v.Get("definitions").([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("no report definitions are specified")
+	}
+
+	period, err := getPeriodName(v)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := make([]*reportDefinition, len(items))
+	for i, item := range items {
+		settings, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid report definition at index %d", i)
+		}
+		name, _ := settings["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("name of the report definition at index %d is required", i)
+		}
+		if slices.ContainsFunc(definitions[:i], func(d *reportDefinition) bool { return d.name == name }) {
+			return nil, fmt.Errorf("duplicate report definition %q", name)
+		}
+		for _, key := range slices.Sorted(maps.Keys(settings)) {
+			if key != "name" && !slices.Contains(reportDefinitionKeys, key) {
+				return nil, fmt.Errorf("unknown key %q in the report definition %q", key, name)
+			}
+		}
+
+		dv := viper.New()
+		for _, key := range reportDefinitionKeys {
+			dv.SetDefault(key, vipers[countCmd].Get(key))
+		}
+		if err := dv.MergeConfigMap(settings); err != nil {
+			return nil, err
+		}
+		dv.Set("since", v.GetString("since"))
+		dv.Set("until", v.GetString("until"))
+		dv.Set("period", period)
+
+		if len(dv.GetStringSlice("schedule-ids")) == 0 {
+			return nil, fmt.Errorf("schedule-ids of the report definition %q is required", name)
+		}
+		tz, err := time.LoadLocation(dv.GetString("time-zone"))
+		if err != nil {
+			return nil, fmt.Errorf("report definition %q: %w", name, err)
+		}
+		sg, err := newShiftGenerator(dv, tz)
+		if err != nil {
+			return nil, fmt.Errorf("report definition %q: %w", name, err)
+		}
+		definitions[i] = &reportDefinition{name: name, tz: tz, scheduleIDs: dv.GetStringSlice("schedule-ids"), sg: sg}
+	}
+
+	return definitions, nil
+}
+
+// getReportSchedules returns the schedules used by the definitions, each of which is fetched once
+// in UTC over the union of the periods of the definitions
+func getReportSchedules(ctx context.Context, client pd.Client, definitions []*reportDefinition) (map[string]*pagerduty.Schedule, error) {
+	var start, end time.Time
+	ids := make([]string, 0)
+	for i, d := range definitions {
+		s, e := d.sg.Period()
+		if i == 0 || s.Before(start) {
+			start = s
+		}
+		if i == 0 || e.After(end) {
+			end = e
+		}
+		for _, id := range d.scheduleIDs {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	schedules := make(map[string]*pagerduty.Schedule, len(ids))
+	for _, id := range ids {
+		schedule, err := getSchedule(ctx, client, time.UTC, id, start.UTC(), end.UTC())
+		if err != nil {
+			return nil, err
+		}
+		schedules[id] = schedule
+	}
+	return schedules, nil
+}
+
+// entriesIn returns the rendered schedule entries overlapping the period
+func entriesIn(entries []pagerduty.RenderedScheduleEntry, start, end time.Time) ([]pagerduty.RenderedScheduleEntry, error) {
+	result := make([]pagerduty.RenderedScheduleEntry, 0, len(entries))
+	for _, entry := range entries {
+		s, err := time.Parse(time.RFC3339, entry.Start)
+		if err != nil {
+			return nil, err
+		}
+		e, err := time.Parse(time.RFC3339, entry.End)
+		if err != nil {
+			return nil, err
+		}
+		if s.Before(end) && e.After(start) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func runReport(ctx context.Context, out io.Writer, client pd.Client, definitions []*reportDefinition) error {
+	schedules, err := getReportSchedules(ctx, client, definitions)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(definitions))
+	rollup := make(map[string]map[string]float64)
+	expectedRollup := make(map[string]float64)
+	for i, d := range definitions {
+		names[i] = d.name
+
+		start, end := d.sg.Period()
+		scheduleNames := make([]string, len(d.scheduleIDs))
+		iters := make([]*pd.ScheduleEntryIter, len(d.scheduleIDs))
+		for j, id := range d.scheduleIDs {
+			schedule := schedules[id]
+			entries, err := entriesIn(schedule.FinalSchedule.RenderedScheduleEntries, start, end)
+			if err != nil {
+				return err
+			}
+			scheduleNames[j] = schedule.Name
+			iters[j], err = pd.NewScheduleEntryIter(schedule.Name, d.tz, entries)
+			if err != nil {
+				return err
+			}
+		}
+
+		counts := make(map[string]map[string]float64)
+		expected := make(map[string]float64)
+		for shift := range d.sg.AllShifts() {
+			if shift.Weight == 0 {
+				continue
+			}
+			for _, iter := range iters {
+				shift.AddDetails(iter)
+			}
+			for _, name := range scheduleNames {
+				expected[name] += shift.Weight * shift.Fraction()
+				for _, detail := range shift.Details[name] {
+					addCount(counts, detail.User, name, detail.Proportion*shift.Weight)
+					addCount(rollup, detail.User, d.name, detail.Proportion*shift.Weight)
+				}
+			}
+		}
+		for _, total := range expected {
+			expectedRollup[d.name] += total
+		}
+
+		fmt.Fprintf(out, "# %s\n\n", d.name)
+		fmt.Fprintf(out, "- Period: %s - %s\n\n", start.Format(dateTimeLayout), end.Format(dateTimeLayout))
+		printSummaryTable(out, slices.Sorted(maps.Keys(counts)), scheduleNames, counts, expected)
+	}
+
+	fmt.Fprintf(out, "# Roll-up\n\n")
+	printSummaryTable(out, slices.Sorted(maps.Keys(rollup)), names, rollup, expectedRollup)
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/abicky/pd-shift/testing/mock"
+	"github.com/spf13/viper"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_runReport(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type definition struct {
+		name         string
+		handoffTimes []string
+		include      []string
+	}
+	tests := []struct {
+		name        string
+		definitions []definition
+		wantSince   string
+		wantUntil   string
+		wantOutput  string
+	}{
+		{
+			name: "Same handoff times",
+			definitions: []definition{
+				{name: "Nights", handoffTimes: []string{"05:00", "17:00"}, include: []string{"working-days:17:00-05:00"}},
+				{name: "Weekends", handoffTimes: []string{"05:00", "17:00"}, include: []string{"non-working-days"}},
+			},
+			wantSince: "2025-06-30 20:00",
+			wantUntil: "2025-07-07 20:00",
+			wantOutput: `# Nights
+
+- Period: Tue, 2025-07-01 05:00+0900 - Tue, 2025-07-08 05:00+0900
+
+| User | Weekly Rotation | Total |
+|------|------|-------|
+| John Smith | 4.00 | 4.00 |
+| Takeshi Arabiki | 1.00 | 1.00 |
+| Total | 5.00 | 5.00 |
+| Expected total | 5.00 | 5.00 |
+
+# Weekends
+
+- Period: Tue, 2025-07-01 05:00+0900 - Tue, 2025-07-08 05:00+0900
+
+| User | Weekly Rotation | Total |
+|------|------|-------|
+| John Smith | 3.50 | 3.50 |
+| Takeshi Arabiki | 0.50 | 0.50 |
+| Total | 4.00 | 4.00 |
+| Expected total | 4.00 | 4.00 |
+
+# Roll-up
+
+| User | Nights | Weekends | Total |
+|------|------|------|-------|
+| John Smith | 4.00 | 3.50 | 7.50 |
+| Takeshi Arabiki | 1.00 | 0.50 | 1.50 |
+| Total | 5.00 | 4.00 | 9.00 |
+| Expected total | 5.00 | 4.00 | 9.00 |
+
+`,
+		},
+		{
+			name: "Different handoff times",
+			definitions: []definition{
+				{name: "Nights", handoffTimes: []string{"05:00", "17:00"}, include: []string{"working-days:17:00-05:00"}},
+				{name: "Days", handoffTimes: []string{"09:00", "18:00"}, include: []string{"working-days:09:00-18:00"}},
+			},
+			wantSince: "2025-06-30 20:00",
+			wantUntil: "2025-07-08 00:00",
+			wantOutput: `# Nights
+
+- Period: Tue, 2025-07-01 05:00+0900 - Tue, 2025-07-08 05:00+0900
+
+| User | Weekly Rotation | Total |
+|------|------|-------|
+| John Smith | 4.00 | 4.00 |
+| Takeshi Arabiki | 1.00 | 1.00 |
+| Total | 5.00 | 5.00 |
+| Expected total | 5.00 | 5.00 |
+
+# Days
+
+- Period: Tue, 2025-07-01 09:00+0900 - Tue, 2025-07-08 09:00+0900
+
+| User | Weekly Rotation | Total |
+|------|------|-------|
+| John Smith | 4.00 | 4.00 |
+| Takeshi Arabiki | 1.00 | 1.00 |
+| Total | 5.00 | 5.00 |
+| Expected total | 5.00 | 5.00 |
+
+# Roll-up
+
+| User | Nights | Days | Total |
+|------|------|------|-------|
+| John Smith | 4.00 | 4.00 | 8.00 |
+| Takeshi Arabiki | 1.00 | 1.00 | 2.00 |
+| Total | 5.00 | 5.00 | 10.00 |
+| Expected total | 5.00 | 5.00 | 10.00 |
+
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock.NewMockClient(ctrl)
+			client.EXPECT().GetScheduleWithContext(t.Context(), "P4DRALL", pagerduty.GetScheduleOptions{
+				TimeZone: "UTC",
+				Since:    tt.wantSince,
+				Until:    tt.wantUntil,
+			}).Return(&pagerduty.Schedule{
+				Name: "Weekly Rotation",
+				FinalSchedule: pagerduty.ScheduleLayer{
+					RenderedScheduleEntries: []pagerduty.RenderedScheduleEntry{
+						{
+							Start: "2025-07-01T05:00:00+09:00",
+							End:   "2025-07-05T09:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-05T09:00:00+09:00",
+							End:   "2025-07-05T15:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "Takeshi Arabiki"},
+						},
+						{
+							Start: "2025-07-05T15:00:00+09:00",
+							End:   "2025-07-07T05:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "John Smith"},
+						},
+						{
+							Start: "2025-07-07T05:00:00+09:00",
+							End:   "2025-07-08T09:00:00+09:00",
+							User:  pagerduty.APIObject{Summary: "Takeshi Arabiki"},
+						},
+					},
+				},
+			}, nil).Times(1)
+
+			definitions := make([]*reportDefinition, 0)
+			for _, d := range tt.definitions {
+				sg, err := pd.NewShiftGenerator(jst, "2025-07-01", "2025-07-08", d.handoffTimes, d.include, []string{"JP holidays", "Sat", "Sun"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				definitions = append(definitions, &reportDefinition{name: d.name, tz: jst, scheduleIDs: []string{"P4DRALL"}, sg: sg})
+			}
+
+			var b bytes.Buffer
+			if err := runReport(t.Context(), &b, client, definitions); err != nil {
+				t.Errorf("runReport() = %v, want nil", err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
+
+func Test_newReportDefinitions(t *testing.T) {
+	countv := viper.New()
+	countv.Set("time-zone", "Asia/Tokyo")
+	countv.Set("handoff-times", []string{"05:00", "17:00"})
+	countv.Set("non-working-days", []string{"Sat", "Sun"})
+	countv.Set("day-type-anchor", "start")
+	countv.Set("handoff-cycle-days", 1)
+	countv.Set("fiscal-year-start", 4)
+	vipers[countCmd] = countv
+	defer delete(vipers, countCmd)
+
+	tests := []struct {
+		name            string
+		definitions     []any
+		wantScheduleIDs map[string][]string
+		wantErr         string
+	}{
+		{
+			name: "Valid",
+			definitions: []any{
+				map[string]any{"name": "SRE", "schedule-ids": []any{"P4DRALL"}},
+				map[string]any{"name": "DB", "schedule-ids": []any{"P5DBALL"}, "handoff-times": []any{"Mon 10:00"}, "time-zone": "UTC"},
+			},
+			wantScheduleIDs: map[string][]string{"SRE": {"P4DRALL"}, "DB": {"P5DBALL"}},
+		},
+		{
+			name:    "No definitions",
+			wantErr: "no report definitions are specified",
+		},
+		{
+			name:        "No name",
+			definitions: []any{map[string]any{"schedule-ids": []any{"P4DRALL"}}},
+			wantErr:     "name of the report definition at index 0 is required",
+		},
+		{
+			name: "Duplicate name",
+			definitions: []any{
+				map[string]any{"name": "SRE", "schedule-ids": []any{"P4DRALL"}},
+				map[string]any{"name": "SRE", "schedule-ids": []any{"P5DBALL"}},
+			},
+			wantErr: `duplicate report definition "SRE"`,
+		},
+		{
+			name:        "Unknown key",
+			definitions: []any{map[string]any{"name": "SRE", "schedule-id": "P4DRALL"}},
+			wantErr:     `unknown key "schedule-id" in the report definition "SRE"`,
+		},
+		{
+			name:        "No schedule IDs",
+			definitions: []any{map[string]any{"name": "SRE"}},
+			wantErr:     `schedule-ids of the report definition "SRE" is required`,
+		},
+		{
+			name:        "Invalid include",
+			definitions: []any{map[string]any{"name": "SRE", "schedule-ids": []any{"P4DRALL"}, "include": []any{"working-dayz"}}},
+			wantErr:     `report definition "SRE": unknown include type "working-dayz"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("since", "2025-07-01")
+			v.Set("until", "2025-08-01")
+			if tt.definitions != nil {
+				v.Set("definitions", tt.definitions)
+			}
+
+			got, err := newReportDefinitions(v)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("newReportDefinitions() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newReportDefinitions() = %v, want nil", err)
+			}
+			if len(got) != len(tt.wantScheduleIDs) {
+				t.Fatalf("len(got) = %d, want %d", len(got), len(tt.wantScheduleIDs))
+			}
+			for _, d := range got {
+				if !slices.Equal(d.scheduleIDs, tt.wantScheduleIDs[d.name]) {
+					t.Errorf("%s: scheduleIDs = %v, want %v", d.name, d.scheduleIDs, tt.wantScheduleIDs[d.name])
+				}
+			}
+		})
+	}
+}
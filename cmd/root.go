@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -25,6 +26,21 @@ var (
 
 var vipers = make(map[*cobra.Command]*viper.Viper)
 
+// configuredFlags are the flags set by bindPFlags from config files or environment variables
+var configuredFlags = make(map[*pflag.Flag]bool)
+
+// isSetByFlag reports whether the key of v is given by a flag of the command rather than
+// config files or environment variables
+func isSetByFlag(v *viper.Viper, key string) bool {
+	for cmd, cv := range vipers {
+		if cv == v {
+			f := cmd.Flags().Lookup(key)
+			return f != nil && f.Changed && !configuredFlags[f]
+		}
+	}
+	return false
+}
+
 var (
 	defaultCommandGroup = &cobra.Group{
 		ID:    "default",
@@ -82,6 +98,8 @@ func init() {
 	rootCmd.PersistentFlags().String("config", "", "Path to config file")
 	rootCmd.PersistentFlags().String("api-key", "", "PagerDuty API key")
 	rootCmd.MarkPersistentFlagRequired("api-key")
+	rootCmd.PersistentFlags().String("profile", "", "Name of the profile in the config file")
+	rootCmd.PersistentFlags().String("ledger-file", "", "Path to the ledger file (default $XDG_DATA_HOME/pd-shift/ledger.jsonl)")
 }
 
 func initConfig() {
@@ -120,9 +138,46 @@ func initConfig() {
 		viper.ReadInConfig()
 	}
 
+	// Merge the profile before Viper.Sub is called by bindPFlags
+	if profile := viper.GetString("profile"); profile != "" {
+		settings, err := profileSettings(viper.GetViper(), profile)
+		cobra.CheckErr(err)
+		cobra.CheckErr(viper.MergeConfigMap(settings))
+	}
+
 	cobra.CheckErr(bindPFlags(viper.GetViper(), rootCmd))
 }
 
+// profileSettings returns the settings in "profiles.<name>" merged over the profile specified by "inherits"
+func profileSettings(v *viper.Viper, name string) (map[string]any, error) {
+	chain := make([]string, 0)
+	profiles := make([]map[string]any, 0)
+	for name != "" {
+		name = strings.ToLower(name)
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("circular inheritance of profiles: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+		chain = append(chain, name)
+
+		profile, ok := v.Get("profiles." + name).(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		profiles = append(profiles, profile)
+		name, _ = profile["inherits"].(string)
+	}
+
+	merged := viper.New()
+	for _, profile := range slices.Backward(profiles) {
+		if err := merged.MergeConfigMap(profile); err != nil {
+			return nil, err
+		}
+	}
+	settings := merged.AllSettings()
+	delete(settings, "inherits")
+	return settings, nil
+}
+
 func bindPFlags(v *viper.Viper, cmd *cobra.Command) error {
 	if err := v.BindPFlags(cmd.Flags()); err != nil {
 		return err
@@ -132,6 +187,10 @@ func bindPFlags(v *viper.Viper, cmd *cobra.Command) error {
 		// Set the default value to prevent Viper.Sub from returning nil
 		v.SetDefault(name, make(map[string]any))
 		subv := v.Sub(name)
+		if subv == nil {
+			// The name collides with a key that is not a map, such as "config"
+			subv = viper.New()
+		}
 		if err := bindPFlags(subv, c); err != nil {
 			return err
 		}
@@ -153,9 +212,17 @@ func bindPFlags(v *viper.Viper, cmd *cobra.Command) error {
 				return
 			}
 
-			if value := v.GetString(f.Name); value != "" {
+			if value, ok := v.Get(f.Name).([]any); ok && slices.ContainsFunc(value, isMap) {
+				// Structured values cannot be set to flags
+				if _, ok := f.Annotations[cobra.BashCompOneRequiredFlag]; ok {
+					f.Annotations[cobra.BashCompOneRequiredFlag] = []string{"false"}
+				}
+			} else if value := v.GetString(f.Name); value != "" {
+				// Persistent flags may be already set by the flag set of the subcommand
+				configuredFlags[f] = !f.Changed
 				cmd.Flags().Set(f.Name, value)
 			} else if value := v.GetStringSlice(f.Name); len(value) > 0 {
+				configuredFlags[f] = !f.Changed
 				cmd.Flags().Set(f.Name, strings.Join(value, ","))
 			}
 		}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func Test_Execute(t *testing.T) {
@@ -73,3 +75,91 @@ func Test_Execute(t *testing.T) {
 		})
 	}
 }
+
+func Test_profileSettings(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	config := `count:
+  time-zone: Asia/Tokyo
+profiles:
+  sre:
+    count:
+      schedule-ids: [P4DRALL]
+      handoff-times: ["05:00", "17:00"]
+  SRE-Weekend:
+    inherits: sre
+    count:
+      include: [non-working-days]
+      handoff-times: ["09:00"]
+  loop1:
+    inherits: loop2
+  loop2:
+    inherits: loop1
+  orphan:
+    inherits: unknown
+`
+	if err := v.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:    "Profile",
+			profile: "sre",
+			want: map[string]any{
+				"count": map[string]any{
+					"schedule-ids":  []any{"P4DRALL"},
+					"handoff-times": []any{"05:00", "17:00"},
+				},
+			},
+		},
+		{
+			name:    "Inheritance",
+			profile: "SRE-Weekend",
+			want: map[string]any{
+				"count": map[string]any{
+					"schedule-ids":  []any{"P4DRALL"},
+					"handoff-times": []any{"09:00"},
+					"include":       []any{"non-working-days"},
+				},
+			},
+		},
+		{
+			name:    "Unknown profile",
+			profile: "db",
+			wantErr: `unknown profile "db"`,
+		},
+		{
+			name:    "Unknown base profile",
+			profile: "orphan",
+			wantErr: `unknown profile "unknown"`,
+		},
+		{
+			name:    "Circular inheritance",
+			profile: "loop1",
+			wantErr: "circular inheritance of profiles: loop1 -> loop2 -> loop1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := profileSettings(v, tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("profileSettings() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("profileSettings() = %v, want nil", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// rollbackTimeout is the timeout of rolling back the created override if the swap fails
+const rollbackTimeout = 30 * time.Second
+
+var swapCmd = &cobra.Command{
+	Use:   "swap <user 1> <shift 1> <user 2> <shift 2>",
+	Short: "Swap on-call shifts between two users",
+	Long: `This command creates a pair of overrides so that user 2 covers shift 1 held by user 1
+and user 1 covers shift 2 held by user 2. Users are specified by their names or IDs,
+and shifts are specified by their start times (e.g. "2025-07-05 17:00").
+Shifts are generated by the configuration of the count subcommand.
+The overrides are created only if --apply is specified, and the first override is deleted
+if the second one cannot be created.`,
+	Args:    cobra.ExactArgs(4),
+	GroupID: defaultCommandGroup.ID,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Prevent showing usage after validation
+		cmd.SilenceUsage = true
+
+		v := vipers[cmd]
+		inheritCountConfig(v, shiftGeneratorKeys...)
+
+		tz, err := time.LoadLocation(v.GetString("time-zone"))
+		if err != nil {
+			return err
+		}
+
+		shifts, err := findShifts(v, tz, args[1], args[3])
+		if err != nil {
+			return err
+		}
+
+		client := pagerduty.NewClient(viper.GetString("api-key"))
+
+		return runSwap(cmd.Context(), os.Stdout, client, tz, v.GetString("schedule-id"), [2]string{args[0], args[2]}, shifts, v.GetBool("apply"))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(swapCmd)
+
+	swapCmd.Flags().String("schedule-id", "", "ID of the schedule")
+	swapCmd.MarkFlagRequired("schedule-id")
+	swapCmd.Flags().Bool("apply", false, "Create the overrides instead of showing the changes")
+}
+
+// findShifts returns the shifts starting at the given times, which are generated by the configuration of v
+func findShifts(v *viper.Viper, tz *time.Location, starts ...string) ([]*pd.Shift, error) {
+	times := make([]time.Time, len(starts))
+	for i, start := range starts {
+		t, err := pd.ParseTime(start, tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shift: %w", err)
+		}
+		times[i] = t
+	}
+
+	since, until := times[0], times[0]
+	for _, t := range times {
+		if t.Before(since) {
+			since = t
+		}
+		if t.After(until) {
+			until = t
+		}
+	}
+	// Generate shifts until the shift starting at the latest time
+	sg, err := newShiftGeneratorInPeriod(v, tz, since.Format(time.RFC3339), until.Add(time.Minute).Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := make([]*pd.Shift, len(times))
+	for shift := range sg.AllShifts() {
+		for i, t := range times {
+			if shift.Start.Equal(t) {
+				shifts[i] = shift
+			}
+		}
+	}
+	for i, shift := range shifts {
+		if shift == nil {
+			return nil, fmt.Errorf("no shift starts at %s", starts[i])
+		}
+	}
+
+	return shifts, nil
+}
+
+func runSwap(ctx context.Context, out io.Writer, client pd.Client, tz *time.Location, scheduleID string, users [2]string, shifts []*pd.Shift, apply bool) error {
+	if shifts[0].Start.Equal(shifts[1].Start) {
+		return errors.New("the two shifts must differ")
+	}
+
+	// Find the holders of the shifts in order of their start times as required by ScheduleEntryIter
+	order := []int{0, 1}
+	if shifts[1].Start.Before(shifts[0].Start) {
+		order = []int{1, 0}
+	}
+	schedule, err := getSchedule(ctx, client, tz, scheduleID, shifts[order[0]].Start, shifts[order[1]].End)
+	if err != nil {
+		return err
+	}
+	iter, err := pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
+	if err != nil {
+		return err
+	}
+	holders := make([]pd.ShiftDetail, 2)
+	for _, i := range order {
+		shift := shifts[i]
+		shift.AddDetails(iter)
+		details := shift.Details[schedule.Name]
+		if len(details) != 1 || !details[0].Start.Equal(shift.Start) || !details[0].End.Equal(shift.End) {
+			return fmt.Errorf("shift from %s to %s is not held by a single user", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout))
+		}
+		if details[0].User != users[i] && details[0].UserID != users[i] {
+			return fmt.Errorf("shift from %s to %s is held by %s, not %s", shift.Start.Format(dateTimeLayout), shift.End.Format(dateTimeLayout), details[0].User, users[i])
+		}
+		holders[i] = details[0]
+	}
+	if holders[0].UserID == holders[1].UserID {
+		return fmt.Errorf("both shifts are held by %s", holders[0].User)
+	}
+
+	overrides := []*pd.Override{
+		{Start: shifts[0].Start, End: shifts[0].End, UserID: holders[1].UserID, User: holders[1].User},
+		{Start: shifts[1].Start, End: shifts[1].End, UserID: holders[0].UserID, User: holders[0].User},
+	}
+	iter, err = pd.NewScheduleEntryIter(schedule.Name, tz, schedule.FinalSchedule.RenderedScheduleEntries)
+	if err != nil {
+		return err
+	}
+	diffs, err := pd.DiffOverrides(overrides, iter)
+	if err != nil {
+		return err
+	}
+	printOverrideDiffs(out, tz, schedule.Name, diffs)
+
+	if !apply {
+		fmt.Fprintf(out, "\nThis is a dry run. Specify --apply to create the overrides.\n")
+		return nil
+	}
+
+	first, err := createOverride(ctx, client, scheduleID, overrides[0])
+	if err != nil {
+		return err
+	}
+	second, err := createOverride(ctx, client, scheduleID, overrides[1])
+	if err != nil {
+		// Roll back even if ctx is canceled, e.g. by an interrupt, so as not to leave the half of the swap
+		rbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+		defer cancel()
+		if rbErr := client.DeleteOverrideWithContext(rbCtx, scheduleID, first.ID); rbErr != nil {
+			return fmt.Errorf("%w (failed to roll back override %s: %v)", err, first.ID, rbErr)
+		}
+		return fmt.Errorf("%w (override %s was rolled back)", err, first.ID)
+	}
+
+	fmt.Fprintf(out, "\n# Created overrides\n\n")
+	for _, o := range []*pagerduty.Override{first, second} {
+		if err := printOverride(out, tz, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+	"github.com/abicky/pd-shift/testing/mock"
+	"github.com/spf13/viper"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_findShifts(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.Set("handoff-times", []string{"05:00", "17:00"})
+	v.Set("day-type-anchor", "start")
+
+	shifts, err := findShifts(v, jst, "2025-07-05 17:00", "2025-07-02 17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]time.Time{
+		{time.Date(2025, time.July, 5, 17, 0, 0, 0, jst), time.Date(2025, time.July, 6, 5, 0, 0, 0, jst)},
+		{time.Date(2025, time.July, 2, 17, 0, 0, 0, jst), time.Date(2025, time.July, 3, 5, 0, 0, 0, jst)},
+	}
+	for i, shift := range shifts {
+		if !shift.Start.Equal(want[i][0]) || !shift.End.Equal(want[i][1]) {
+			t.Errorf("shifts[%d] = %v - %v, want %v - %v", i, shift.Start, shift.End, want[i][0], want[i][1])
+		}
+	}
+
+	if _, err := findShifts(v, jst, "2025-07-05 18:00"); err == nil || err.Error() != "no shift starts at 2025-07-05 18:00" {
+		t.Errorf("err = %v, want no shift starts at 2025-07-05 18:00", err)
+	}
+}
+
+func Test_runSwap(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newShifts := func() []*pd.Shift {
+		return []*pd.Shift{
+			pd.NewShift(time.Date(2025, time.July, 5, 17, 0, 0, 0, jst), time.Date(2025, time.July, 6, 5, 0, 0, 0, jst)),
+			pd.NewShift(time.Date(2025, time.July, 2, 17, 0, 0, 0, jst), time.Date(2025, time.July, 3, 5, 0, 0, 0, jst)),
+		}
+	}
+	takeshiOverride := pagerduty.Override{
+		Start: "2025-07-05T17:00:00+09:00",
+		End:   "2025-07-06T05:00:00+09:00",
+		User:  pagerduty.APIObject{ID: "PTAKESH", Type: "user_reference"},
+	}
+	johnOverride := pagerduty.Override{
+		Start: "2025-07-02T17:00:00+09:00",
+		End:   "2025-07-03T05:00:00+09:00",
+		User:  pagerduty.APIObject{ID: "PJOHN12", Type: "user_reference"},
+	}
+
+	tests := []struct {
+		name       string
+		users      [2]string
+		sameShifts bool
+		apply      bool
+		setup      func(client *mock.MockClient, cancel context.CancelFunc)
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:  "Dry run",
+			users: [2]string{"John Smith", "PTAKESH"},
+			wantOutput: `# Weekly Rotation
+
+- Wed, 2025-07-02 17:00+0900 - Thu, 2025-07-03 05:00+0900
+    - Takeshi Arabiki (17:00 - 05:00)
+    + John Smith
+- Sat, 2025-07-05 17:00+0900 - Sun, 2025-07-06 05:00+0900
+    - John Smith (17:00 - 05:00)
+    + Takeshi Arabiki
+
+This is a dry run. Specify --apply to create the overrides.
+`,
+		},
+		{
+			name:  "Apply",
+			users: [2]string{"John Smith", "Takeshi Arabiki"},
+			apply: true,
+			setup: func(client *mock.MockClient, _ context.CancelFunc) {
+				gomock.InOrder(
+					client.EXPECT().CreateOverrideWithContext(gomock.Any(), "P4DRALL", takeshiOverride).
+						Return(&pagerduty.Override{ID: "PO1", Start: takeshiOverride.Start, End: takeshiOverride.End, User: pagerduty.APIObject{Summary: "Takeshi Arabiki"}}, nil),
+					client.EXPECT().CreateOverrideWithContext(gomock.Any(), "P4DRALL", johnOverride).
+						Return(&pagerduty.Override{ID: "PO2", Start: johnOverride.Start, End: johnOverride.End, User: pagerduty.APIObject{Summary: "John Smith"}}, nil),
+				)
+			},
+			wantOutput: `# Weekly Rotation
+
+- Wed, 2025-07-02 17:00+0900 - Thu, 2025-07-03 05:00+0900
+    - Takeshi Arabiki (17:00 - 05:00)
+    + John Smith
+- Sat, 2025-07-05 17:00+0900 - Sun, 2025-07-06 05:00+0900
+    - John Smith (17:00 - 05:00)
+    + Takeshi Arabiki
+
+# Created overrides
+
+- Sat, 2025-07-05 17:00+0900 - Sun, 2025-07-06 05:00+0900: Takeshi Arabiki (PO1)
+- Wed, 2025-07-02 17:00+0900 - Thu, 2025-07-03 05:00+0900: John Smith (PO2)
+`,
+		},
+		{
+			name:  "Roll back",
+			users: [2]string{"John Smith", "Takeshi Arabiki"},
+			apply: true,
+			setup: func(client *mock.MockClient, cancel context.CancelFunc) {
+				gomock.InOrder(
+					client.EXPECT().CreateOverrideWithContext(gomock.Any(), "P4DRALL", takeshiOverride).
+						Return(&pagerduty.Override{ID: "PO1"}, nil),
+					// Simulate an interrupt while creating the second override
+					client.EXPECT().CreateOverrideWithContext(gomock.Any(), "P4DRALL", johnOverride).
+						DoAndReturn(func(context.Context, string, pagerduty.Override) (*pagerduty.Override, error) {
+							cancel()
+							return nil, errors.New("server error")
+						}),
+					client.EXPECT().DeleteOverrideWithContext(gomock.Any(), "P4DRALL", "PO1").
+						DoAndReturn(func(ctx context.Context, _, _ string) error {
+							return ctx.Err()
+						}),
+				)
+			},
+			wantErr: "failed to create PagerDuty override: server error (override PO1 was rolled back)",
+		},
+		{
+			name:    "Wrong holder",
+			users:   [2]string{"Takeshi Arabiki", "John Smith"},
+			wantErr: "shift from Wed, 2025-07-02 17:00+0900 to Thu, 2025-07-03 05:00+0900 is held by Takeshi Arabiki, not John Smith",
+		},
+		{
+			name:       "Same shifts",
+			users:      [2]string{"John Smith", "John Smith"},
+			sameShifts: true,
+			wantErr:    "the two shifts must differ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := mock.NewMockClient(ctrl)
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			shifts := newShifts()
+			if tt.sameShifts {
+				shifts[1] = shifts[0]
+			}
+
+			// The same shifts are rejected before fetching the schedule
+			if !tt.sameShifts {
+				client.EXPECT().GetScheduleWithContext(ctx, "P4DRALL", pagerduty.GetScheduleOptions{
+					TimeZone: jst.String(),
+					Since:    "2025-07-02 17:00",
+					Until:    "2025-07-06 05:00",
+				}).Return(&pagerduty.Schedule{
+					Name: "Weekly Rotation",
+					FinalSchedule: pagerduty.ScheduleLayer{
+						RenderedScheduleEntries: []pagerduty.RenderedScheduleEntry{
+							{
+								Start: "2025-07-02T17:00:00+09:00",
+								End:   "2025-07-05T09:00:00+09:00",
+								User:  pagerduty.APIObject{ID: "PTAKESH", Summary: "Takeshi Arabiki"},
+							},
+							{
+								Start: "2025-07-05T09:00:00+09:00",
+								End:   "2025-07-06T05:00:00+09:00",
+								User:  pagerduty.APIObject{ID: "PJOHN12", Summary: "John Smith"},
+							},
+						},
+					},
+				}, nil)
+			}
+			if tt.setup != nil {
+				tt.setup(client, cancel)
+			}
+
+			var b bytes.Buffer
+
+			err := runSwap(ctx, &b, client, jst, "P4DRALL", tt.users, shifts, tt.apply)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.wantOutput {
+				t.Errorf("b.String() = %v, want %v", b.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package pd
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Swap is an override handing over a whole shift of a schedule from one participant to another
+type Swap struct {
+	Shift        *Shift
+	ScheduleName string
+	From         *Participant
+	To           *Participant
+	// Weight is the weighted count moved by the swap
+	Weight float64
+}
+
+type BalanceProposal struct {
+	Swaps []*Swap
+	// Current and Projected are the weighted counts of each participant before and after the swaps
+	Current   map[string]float64
+	Projected map[string]float64
+	Mean      float64
+	// Balanced reports whether all the projected counts are within the tolerance of the mean
+	Balanced bool
+}
+
+// swapCandidate is a shift of a schedule that can be handed over as a whole
+type swapCandidate struct {
+	shift        *Shift
+	scheduleName string
+	owner        string
+	weight       float64
+}
+
+// ProposeSwaps proposes swaps of shifts starting at or after now so that the weighted count of each participant
+// is within the tolerance of the mean. Shifts must have details, and each swap is chosen greedily
+// so that it reduces the variance of the counts the most.
+func ProposeSwaps(shifts []*Shift, now time.Time, participants []*Participant, tolerance float64) (*BalanceProposal, error) {
+	if len(participants) == 0 {
+		return nil, errors.New("no participants provided")
+	}
+	if tolerance < 0 {
+		return nil, errors.New("tolerance must not be negative")
+	}
+
+	byName := make(map[string]*Participant, len(participants))
+	unavailable := make(map[string]map[string]bool, len(participants))
+	for _, p := range participants {
+		byName[p.Name] = p
+		dates, err := newUnavailableDates(p)
+		if err != nil {
+			return nil, err
+		}
+		unavailable[p.Name] = dates
+	}
+
+	current := make(map[string]float64, len(participants))
+	candidates := make([]*swapCandidate, 0)
+	total := 0.0
+	for _, shift := range shifts {
+		if shift.Weight == 0 {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(shift.Details)) {
+			details := shift.Details[name]
+			for _, detail := range details {
+				current[detail.User] += detail.Proportion * shift.Weight
+				total += detail.Proportion * shift.Weight
+			}
+			if len(details) != 1 || shift.Start.Before(now) {
+				continue
+			}
+			if _, ok := byName[details[0].User]; !ok {
+				continue
+			}
+			if start, end := shift.CountedPeriod(); !details[0].Start.Equal(start) || !details[0].End.Equal(end) {
+				continue
+			}
+			candidates = append(candidates, &swapCandidate{
+				shift:        shift,
+				scheduleName: name,
+				owner:        details[0].User,
+				weight:       details[0].Proportion * shift.Weight,
+			})
+		}
+	}
+
+	proposal := &BalanceProposal{
+		Swaps:     make([]*Swap, 0),
+		Current:   current,
+		Projected: make(map[string]float64, len(participants)),
+		Mean:      total / float64(len(participants)),
+	}
+	for _, p := range participants {
+		proposal.Projected[p.Name] = current[p.Name]
+	}
+
+	for !isBalanced(proposal.Projected, proposal.Mean, tolerance) {
+		var best *swapCandidate
+		var bestTo *Participant
+		bestGain := 1e-9
+		for _, c := range candidates {
+			for _, p := range participants {
+				if p.Name == c.owner || overlapDates(unavailable[p.Name], c.shift) {
+					continue
+				}
+				// Moving the weight w from a to b reduces the sum of squared deviations by 2w(a - b - w)
+				if gain := c.weight * (proposal.Projected[c.owner] - proposal.Projected[p.Name] - c.weight); gain > bestGain {
+					best, bestTo, bestGain = c, p, gain
+				}
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		proposal.Swaps = append(proposal.Swaps, &Swap{
+			Shift:        best.shift,
+			ScheduleName: best.scheduleName,
+			From:         byName[best.owner],
+			To:           bestTo,
+			Weight:       best.weight,
+		})
+		proposal.Projected[best.owner] -= best.weight
+		proposal.Projected[bestTo.Name] += best.weight
+		// Each shift is swapped at most once to keep the proposal minimal
+		candidates = slices.DeleteFunc(candidates, func(c *swapCandidate) bool {
+			return c == best
+		})
+	}
+	proposal.Balanced = isBalanced(proposal.Projected, proposal.Mean, tolerance)
+
+	return proposal, nil
+}
+
+func isBalanced(counts map[string]float64, mean, tolerance float64) bool {
+	for _, count := range counts {
+		// Allow rounding errors of floating point numbers
+		if math.Abs(count-mean) > tolerance+1e-9 {
+			return false
+		}
+	}
+	return true
+}
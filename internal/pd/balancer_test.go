@@ -0,0 +1,91 @@
+package pd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestProposeSwaps(t *testing.T) {
+	newShifts := func(users ...string) []*pd.Shift {
+		shifts := make([]*pd.Shift, len(users))
+		iter := make([]entry, len(users))
+		for i, user := range users {
+			start := time.Date(2025, time.August, 1+i, 17, 0, 0, 0, time.UTC)
+			end := time.Date(2025, time.August, 2+i, 5, 0, 0, 0, time.UTC)
+			shifts[i] = pd.NewShift(start, end)
+			iter[i] = entry{start: start.Format(time.RFC3339), end: end.Format(time.RFC3339), user: user}
+		}
+		it := newScheduleEntryIter(t, "primary", time.UTC, iter)
+		for _, shift := range shifts {
+			shift.AddDetails(it)
+		}
+		return shifts
+	}
+
+	tests := []struct {
+		name         string
+		shifts       []*pd.Shift
+		now          time.Time
+		participants []*pd.Participant
+		tolerance    float64
+		want         []string
+		wantBalanced bool
+	}{
+		{
+			name:         "Balanced",
+			shifts:       newShifts("Alice", "Bob", "Alice", "Bob"),
+			participants: []*pd.Participant{{Name: "Alice"}, {Name: "Bob"}},
+			tolerance:    0,
+			want:         []string{},
+			wantBalanced: true,
+		},
+		{
+			name:         "Swap future shifts",
+			shifts:       newShifts("Alice", "Alice", "Alice", "Alice", "Alice", "Bob"),
+			now:          time.Date(2025, time.August, 3, 0, 0, 0, 0, time.UTC),
+			participants: []*pd.Participant{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}},
+			tolerance:    0.5,
+			want: []string{
+				"2025-08-03T17:00:00Z Alice -> Carol",
+				"2025-08-04T17:00:00Z Alice -> Bob",
+				"2025-08-05T17:00:00Z Alice -> Carol",
+			},
+			wantBalanced: true,
+		},
+		{
+			name:   "Unavailable dates",
+			shifts: newShifts("Alice", "Alice", "Alice", "Alice"),
+			participants: []*pd.Participant{
+				{Name: "Alice"},
+				{Name: "Bob", UnavailableDates: []string{"2025-08-01", "2025-08-02", "2025-08-03"}},
+			},
+			tolerance: 0,
+			want: []string{
+				"2025-08-04T17:00:00Z Alice -> Bob",
+			},
+			wantBalanced: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposal, err := pd.ProposeSwaps(tt.shifts, tt.now, tt.participants, tt.tolerance)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			swaps := make([]string, len(proposal.Swaps))
+			for i, swap := range proposal.Swaps {
+				swaps[i] = swap.Shift.Start.Format(time.RFC3339) + " " + swap.From.Name + " -> " + swap.To.Name
+			}
+			if !reflect.DeepEqual(swaps, tt.want) {
+				t.Errorf("swaps = %v, want %v", swaps, tt.want)
+			}
+			if proposal.Balanced != tt.wantBalanced {
+				t.Errorf("proposal.Balanced = %v, want %v", proposal.Balanced, tt.wantBalanced)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package pd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	workingDaysClass    = "working-days"
+	nonWorkingDaysClass = "non-working-days"
+)
+
+// DayClass is a named set of non-working days such as "holidays" or "weekends"
+type DayClass struct {
+	Name string
+	Days []string
+}
+
+type Day struct {
+	Date         time.Time
+	Class        string
+	NonWorking   bool
+	MatchedRules []string
+}
+
+type dayClass struct {
+	name             string
+	nonWorkingDaySet *nonWorkingDaySet
+}
+
+// dayClassifier classifies days into day classes. Classes are ordered by precedence.
+type dayClassifier struct {
+	classes []*dayClass
+}
+
+func ClassifyDays(since, until string, nonWorkingDays []string, dayClasses []DayClass) ([]Day, error) {
+	classifier, err := newDayClassifier(dayClasses, nonWorkingDays)
+	if err != nil {
+		return nil, err
+	}
+
+	sinceTime, err := time.Parse(time.DateOnly, since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since value: %w", err)
+	}
+	untilTime, err := time.Parse(time.DateOnly, until)
+	if err != nil {
+		return nil, fmt.Errorf("invalid until value: %w", err)
+	}
+
+	days := make([]Day, 0)
+	for t := sinceTime; t.Before(untilTime); t = t.AddDate(0, 0, 1) {
+		day := Day{
+			Date:         t,
+			Class:        workingDaysClass,
+			MatchedRules: []string{},
+		}
+		if c := classifier.find(t); c != nil {
+			day.Class = c.name
+			day.NonWorking = true
+			day.MatchedRules = c.nonWorkingDaySet.matchedRules(t)
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func newDayClassifier(dayClasses []DayClass, nonWorkingDays []string) (*dayClassifier, error) {
+	classes := make([]*dayClass, 0, len(dayClasses)+1)
+	for _, dc := range dayClasses {
+		if dc.Name == "" || dc.Name == workingDaysClass || dc.Name == nonWorkingDaysClass || strings.Contains(dc.Name, ":") {
+			return nil, fmt.Errorf("invalid day class name %q", dc.Name)
+		}
+		if slices.ContainsFunc(classes, func(c *dayClass) bool { return c.name == dc.Name }) {
+			return nil, fmt.Errorf("duplicate day class %q", dc.Name)
+		}
+		nwds, err := newNonWorkingDaySet(dc.Days)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day class %q: %w", dc.Name, err)
+		}
+		classes = append(classes, &dayClass{name: dc.Name, nonWorkingDaySet: nwds})
+	}
+
+	nwds, err := newNonWorkingDaySet(nonWorkingDays)
+	if err != nil {
+		return nil, err
+	}
+	classes = append(classes, &dayClass{name: nonWorkingDaysClass, nonWorkingDaySet: nwds})
+
+	return &dayClassifier{classes: classes}, nil
+}
+
+// find returns the day class with the highest precedence covering t, or nil if t is a working day
+func (c *dayClassifier) find(t time.Time) *dayClass {
+	i := slices.IndexFunc(c.classes, func(dc *dayClass) bool {
+		return dc.nonWorkingDaySet.cover(t)
+	})
+	if i == -1 {
+		return nil
+	}
+	return c.classes[i]
+}
+
+func (c *dayClassifier) classify(t time.Time) string {
+	if dc := c.find(t); dc != nil {
+		return dc.name
+	}
+	return workingDaysClass
+}
+
+// names returns the names of the day classes that can be assigned to days
+func (c *dayClassifier) names() []string {
+	names := []string{workingDaysClass}
+	for _, dc := range c.classes {
+		if len(dc.nonWorkingDaySet.nonWorkingDays) > 0 {
+			names = append(names, dc.name)
+		}
+	}
+	return names
+}
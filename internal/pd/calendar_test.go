@@ -0,0 +1,110 @@
+package pd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestClassifyDays(t *testing.T) {
+	tests := []struct {
+		name           string
+		since          string
+		until          string
+		nonWorkingDays []string
+		dayClasses     []pd.DayClass
+		want           []pd.Day
+	}{
+		{
+			name:           "No non-working days",
+			since:          "2025-07-19",
+			until:          "2025-07-21",
+			nonWorkingDays: []string{},
+			want: []pd.Day{
+				{
+					Date:         time.Date(2025, time.July, 19, 0, 0, 0, 0, time.UTC),
+					Class:        "working-days",
+					NonWorking:   false,
+					MatchedRules: []string{},
+				},
+				{
+					Date:         time.Date(2025, time.July, 20, 0, 0, 0, 0, time.UTC),
+					Class:        "working-days",
+					NonWorking:   false,
+					MatchedRules: []string{},
+				},
+			},
+		},
+		{
+			name:           "Multiple rules match",
+			since:          "2025-07-19",
+			until:          "2025-07-22",
+			nonWorkingDays: []string{"JP holidays", "Saturday", "Sun", "July 21"},
+			want: []pd.Day{
+				{
+					Date:         time.Date(2025, time.July, 19, 0, 0, 0, 0, time.UTC),
+					Class:        "non-working-days",
+					NonWorking:   true,
+					MatchedRules: []string{"Sat"},
+				},
+				{
+					Date:         time.Date(2025, time.July, 20, 0, 0, 0, 0, time.UTC),
+					Class:        "non-working-days",
+					NonWorking:   true,
+					MatchedRules: []string{"Sun"},
+				},
+				{
+					Date:         time.Date(2025, time.July, 21, 0, 0, 0, 0, time.UTC),
+					Class:        "non-working-days",
+					NonWorking:   true,
+					MatchedRules: []string{"JP holidays (Marine Day)", "Jul 21"},
+				},
+			},
+		},
+		{
+			name:           "With day classes",
+			since:          "2025-07-19",
+			until:          "2025-07-22",
+			nonWorkingDays: []string{"Jul 21"},
+			dayClasses: []pd.DayClass{
+				{Name: "holidays", Days: []string{"JP holidays"}},
+				{Name: "weekends", Days: []string{"Sat", "Sun"}},
+			},
+			want: []pd.Day{
+				{
+					Date:         time.Date(2025, time.July, 19, 0, 0, 0, 0, time.UTC),
+					Class:        "weekends",
+					NonWorking:   true,
+					MatchedRules: []string{"Sat"},
+				},
+				{
+					Date:         time.Date(2025, time.July, 20, 0, 0, 0, 0, time.UTC),
+					Class:        "weekends",
+					NonWorking:   true,
+					MatchedRules: []string{"Sun"},
+				},
+				{
+					Date:         time.Date(2025, time.July, 21, 0, 0, 0, 0, time.UTC),
+					Class:        "holidays",
+					NonWorking:   true,
+					MatchedRules: []string{"JP holidays (Marine Day)"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := pd.ClassifyDays(tt.since, tt.until, tt.nonWorkingDays, tt.dayClasses)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(days, tt.want) {
+				t.Errorf("days = %v, want %v", days, tt.want)
+			}
+		})
+	}
+}
@@ -8,4 +8,7 @@ import (
 
 type Client interface {
 	GetScheduleWithContext(ctx context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error)
+	ListOverridesWithContext(ctx context.Context, id string, o pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error)
+	CreateOverrideWithContext(ctx context.Context, id string, o pagerduty.Override) (*pagerduty.Override, error)
+	DeleteOverrideWithContext(ctx context.Context, scheduleID, overrideID string) error
 }
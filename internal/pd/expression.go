@@ -0,0 +1,570 @@
+package pd
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	clockRegexp = regexp.MustCompile(`\A\d{2}:\d{2}\z`)
+	dateRegexp  = regexp.MustCompile(`\A\d{4}-\d{2}-\d{2}\z`)
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type valueType int
+
+const (
+	boolType valueType = iota
+	numberType
+	stringType
+	timeType
+)
+
+func (t valueType) String() string {
+	switch t {
+	case boolType:
+		return "bool"
+	case numberType:
+		return "number"
+	case stringType:
+		return "string"
+	default:
+		return "time"
+	}
+}
+
+type evalContext struct {
+	shift           *Shift
+	dayClassifier   *dayClassifier
+	handoffSchedule *handoffSchedule
+}
+
+type exprNode interface {
+	valueType() valueType
+	eval(ctx *evalContext) any
+}
+
+type literalNode struct {
+	typ   valueType
+	value any
+}
+
+type variableNode struct {
+	name string
+}
+
+type callNode struct {
+	fn   *function
+	args []exprNode
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+type logicalNode struct {
+	op          string
+	left, right exprNode
+}
+
+type comparisonNode struct {
+	op          string
+	left, right exprNode
+}
+
+// formatNode converts a time into a string to compare it with a string literal
+type formatNode struct {
+	operand exprNode
+	layout  string
+}
+
+type function struct {
+	params []valueType
+	result valueType
+	call   func(ctx *evalContext, args []any) any
+}
+
+var functions = map[string]*function{
+	"weekday": {
+		params: []valueType{timeType},
+		result: stringType,
+		call: func(_ *evalContext, args []any) any {
+			return args[0].(time.Time).Format("Mon")
+		},
+	},
+	"date": {
+		params: []valueType{timeType},
+		result: stringType,
+		call: func(_ *evalContext, args []any) any {
+			return args[0].(time.Time).Format(time.DateOnly)
+		},
+	},
+	"time": {
+		params: []valueType{timeType},
+		result: stringType,
+		call: func(_ *evalContext, args []any) any {
+			return args[0].(time.Time).Format("15:04")
+		},
+	},
+	"dayClass": {
+		params: []valueType{timeType},
+		result: stringType,
+		call: func(ctx *evalContext, args []any) any {
+			return ctx.dayClassifier.classify(args[0].(time.Time))
+		},
+	},
+	"working": {
+		params: []valueType{timeType},
+		result: boolType,
+		call: func(ctx *evalContext, args []any) any {
+			return ctx.dayClassifier.classify(args[0].(time.Time)) == workingDaysClass
+		},
+	},
+	"nonWorking": {
+		params: []valueType{timeType},
+		result: boolType,
+		call: func(ctx *evalContext, args []any) any {
+			return ctx.dayClassifier.classify(args[0].(time.Time)) != workingDaysClass
+		},
+	},
+	"index": {
+		params: []valueType{},
+		result: numberType,
+		call: func(ctx *evalContext, _ []any) any {
+			return float64(slices.Index(ctx.handoffSchedule.on(ctx.shift.Start), ctx.shift.Start.Format("15:04")))
+		},
+	},
+	"duration": {
+		params: []valueType{},
+		result: numberType,
+		call: func(ctx *evalContext, _ []any) any {
+			return ctx.shift.End.Sub(ctx.shift.Start).Hours()
+		},
+	},
+}
+
+// selectCondition is an include condition defined by a boolean expression
+type selectCondition struct {
+	expr            exprNode
+	dayClassifier   *dayClassifier
+	handoffSchedule *handoffSchedule
+}
+
+var _ includeCondition = (*selectCondition)(nil)
+
+func (c *selectCondition) match(shift *Shift, _ string) bool {
+	return c.expr.eval(&evalContext{
+		shift:           shift,
+		dayClassifier:   c.dayClassifier,
+		handoffSchedule: c.handoffSchedule,
+	}).(bool)
+}
+
+// expressionError points at the position where a select expression is invalid
+type expressionError struct {
+	expression string
+	pos        int
+	message    string
+}
+
+func (e *expressionError) Error() string {
+	return fmt.Sprintf("%s at position %d\n  %s\n  %s^", e.message, e.pos+1, e.expression, strings.Repeat(" ", e.pos))
+}
+
+func newSelectCondition(expression string, schedule *handoffSchedule, classifier *dayClassifier) (*selectCondition, error) {
+	p := &parser{expression: expression}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected token %q", tok.text)
+	}
+	if expr.valueType() != boolType {
+		return nil, p.errorf(p.tokens[0], "expression must be bool, but got %s", expr.valueType())
+	}
+
+	return &selectCondition{
+		expr:            expr,
+		dayClassifier:   classifier,
+		handoffSchedule: schedule,
+	}, nil
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	index      int
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &expressionError{
+		expression: p.expression,
+		pos:        tok.pos,
+		message:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *parser) tokenize() error {
+	s := p.expression
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenIdent, text: s[i:j], pos: i})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			p.tokens = append(p.tokens, token{kind: tokenNumber, text: s[i:j], pos: i})
+			i = j
+		case c == '\'' || c == '"':
+			j := strings.IndexByte(s[i+1:], s[i])
+			if j == -1 {
+				return p.errorf(token{pos: i}, "unterminated string")
+			}
+			p.tokens = append(p.tokens, token{kind: tokenString, text: s[i+1 : i+1+j], pos: i})
+			i += j + 2
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", ","} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return p.errorf(token{pos: i}, "unexpected character %q", c)
+			}
+			p.tokens = append(p.tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEOF, text: "end of expression", pos: len(s)})
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.index]
+	if tok.kind != tokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	return tok.kind == tokenOperator && slices.Contains(ops, tok.text)
+}
+
+func (p *parser) expect(op string) error {
+	if tok := p.next(); tok.kind != tokenOperator || tok.text != op {
+		return p.errorf(tok, "expected %q, but got %q", op, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (exprNode, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (exprNode, error) {
+	return p.parseLogical("&&", p.parseComparison)
+}
+
+func (p *parser) parseLogical(op string, parseOperand func() (exprNode, error)) (exprNode, error) {
+	leftTok := p.peek()
+	left, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(op) {
+		p.next()
+		rightTok := p.peek()
+		right, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if left.valueType() != boolType {
+			return nil, p.errorf(leftTok, "operand of %q must be bool, but got %s", op, left.valueType())
+		}
+		if right.valueType() != boolType {
+			return nil, p.errorf(rightTok, "operand of %q must be bool, but got %s", op, right.valueType())
+		}
+		left = &logicalNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (exprNode, error) {
+	leftTok := p.peek()
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("==", "!=", "<", "<=", ">", ">=") {
+		return left, nil
+	}
+
+	opTok := p.next()
+	rightTok := p.peek()
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare a time with a string literal such as '17:00' or '2025-12-31'
+	if left.valueType() == timeType && right.valueType() == stringType {
+		if left, err = p.formatTime(left, right, rightTok); err != nil {
+			return nil, err
+		}
+	} else if left.valueType() == stringType && right.valueType() == timeType {
+		if right, err = p.formatTime(right, left, leftTok); err != nil {
+			return nil, err
+		}
+	}
+
+	if left.valueType() != right.valueType() {
+		return nil, p.errorf(opTok, "cannot compare %s with %s", left.valueType(), right.valueType())
+	}
+	if left.valueType() == boolType && opTok.text != "==" && opTok.text != "!=" {
+		return nil, p.errorf(opTok, "operator %q is not defined for bool", opTok.text)
+	}
+
+	return &comparisonNode{op: opTok.text, left: left, right: right}, nil
+}
+
+func (p *parser) formatTime(t, s exprNode, tok token) (exprNode, error) {
+	lit, ok := s.(*literalNode)
+	if !ok {
+		return nil, p.errorf(tok, "time can be compared only with a string literal")
+	}
+	switch str := lit.value.(string); {
+	case clockRegexp.MatchString(str):
+		return &formatNode{operand: t, layout: "15:04"}, nil
+	case dateRegexp.MatchString(str):
+		return &formatNode{operand: t, layout: time.DateOnly}, nil
+	default:
+		return nil, p.errorf(tok, "%q is neither in the format HH:MM nor YYYY-MM-DD", str)
+	}
+}
+
+func (p *parser) parseUnary() (exprNode, error) {
+	if p.isOperator("!") {
+		p.next()
+		tok := p.peek()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operand.valueType() != boolType {
+			return nil, p.errorf(tok, "operand of \"!\" must be bool, but got %s", operand.valueType())
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return &literalNode{typ: stringType, value: tok.text}, nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return &literalNode{typ: numberType, value: n}, nil
+	case tokenIdent:
+		if p.isOperator("(") {
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "true", "false":
+			return &literalNode{typ: boolType, value: tok.text == "true"}, nil
+		case "start", "end":
+			return &variableNode{name: tok.text}, nil
+		default:
+			return nil, p.errorf(tok, "unknown identifier %q", tok.text)
+		}
+	case tokenEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	case tokenOperator:
+		if tok.text == "(" {
+			expr, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+	}
+	return nil, p.errorf(tok, "unexpected token %q", tok.text)
+}
+
+func (p *parser) parseCall(name token) (exprNode, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q", name.text)
+	}
+	p.next() // "("
+
+	args := make([]exprNode, 0)
+	for !p.isOperator(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		tok := p.peek()
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) < len(fn.params) && arg.valueType() != fn.params[len(args)] {
+			return nil, p.errorf(tok, "argument %d of %s must be %s, but got %s", len(args)+1, name.text, fn.params[len(args)], arg.valueType())
+		}
+		args = append(args, arg)
+	}
+	p.next() // ")"
+
+	if len(args) != len(fn.params) {
+		return nil, p.errorf(name, "%s takes %d argument(s), but got %d", name.text, len(fn.params), len(args))
+	}
+
+	return &callNode{fn: fn, args: args}, nil
+}
+
+func (n *literalNode) valueType() valueType {
+	return n.typ
+}
+
+func (n *literalNode) eval(_ *evalContext) any {
+	return n.value
+}
+
+func (n *variableNode) valueType() valueType {
+	return timeType
+}
+
+func (n *variableNode) eval(ctx *evalContext) any {
+	if n.name == "start" {
+		return ctx.shift.Start
+	}
+	return ctx.shift.End
+}
+
+func (n *callNode) valueType() valueType {
+	return n.fn.result
+}
+
+func (n *callNode) eval(ctx *evalContext) any {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.eval(ctx)
+	}
+	return n.fn.call(ctx, args)
+}
+
+func (n *notNode) valueType() valueType {
+	return boolType
+}
+
+func (n *notNode) eval(ctx *evalContext) any {
+	return !n.operand.eval(ctx).(bool)
+}
+
+func (n *logicalNode) valueType() valueType {
+	return boolType
+}
+
+func (n *logicalNode) eval(ctx *evalContext) any {
+	if n.op == "&&" {
+		return n.left.eval(ctx).(bool) && n.right.eval(ctx).(bool)
+	}
+	return n.left.eval(ctx).(bool) || n.right.eval(ctx).(bool)
+}
+
+func (n *formatNode) valueType() valueType {
+	return stringType
+}
+
+func (n *formatNode) eval(ctx *evalContext) any {
+	return n.operand.eval(ctx).(time.Time).Format(n.layout)
+}
+
+func (n *comparisonNode) valueType() valueType {
+	return boolType
+}
+
+func (n *comparisonNode) eval(ctx *evalContext) any {
+	var c int
+	switch l := n.left.eval(ctx).(type) {
+	case bool:
+		r := n.right.eval(ctx).(bool)
+		if l != r {
+			c = 1
+		}
+	case float64:
+		c = cmp.Compare(l, n.right.eval(ctx).(float64))
+	case string:
+		c = strings.Compare(l, n.right.eval(ctx).(string))
+	case time.Time:
+		c = l.Compare(n.right.eval(ctx).(time.Time))
+	}
+
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
@@ -0,0 +1,84 @@
+package pd
+
+import (
+	"maps"
+	"math"
+	"slices"
+)
+
+type Fairness struct {
+	// Mean, StdDev, Min, Max, and Gini are the statistics of the counts per FTE
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	Gini   float64
+	// Users are sorted by user names
+	Users []*UserFairness
+}
+
+type UserFairness struct {
+	User  string
+	Count float64
+	FTE   float64
+	// Target is the total count prorated by the FTE
+	Target    float64
+	Deviation float64
+}
+
+// NewFairness computes the fairness statistics of the counts. Users not in fte are regarded as full-time,
+// and users only in fte are regarded as having no shifts.
+func NewFairness(counts map[string]float64, fte map[string]float64) *Fairness {
+	users := slices.Collect(maps.Keys(counts))
+	for user := range fte {
+		if !slices.Contains(users, user) {
+			users = append(users, user)
+		}
+	}
+	slices.Sort(users)
+
+	f := &Fairness{Users: make([]*UserFairness, len(users))}
+	if len(users) == 0 {
+		return f
+	}
+
+	total := 0.0
+	totalFTE := 0.0
+	for i, user := range users {
+		uf := &UserFairness{User: user, Count: counts[user], FTE: 1}
+		if v, ok := fte[user]; ok {
+			uf.FTE = v
+		}
+		total += uf.Count
+		totalFTE += uf.FTE
+		f.Users[i] = uf
+	}
+
+	normalized := make([]float64, len(users))
+	for i, uf := range f.Users {
+		uf.Target = total * uf.FTE / totalFTE
+		uf.Deviation = uf.Count - uf.Target
+		normalized[i] = uf.Count / uf.FTE
+	}
+
+	n := float64(len(normalized))
+	f.Min = slices.Min(normalized)
+	f.Max = slices.Max(normalized)
+	for _, x := range normalized {
+		f.Mean += x / n
+	}
+	variance := 0.0
+	diffSum := 0.0
+	for _, x := range normalized {
+		variance += (x - f.Mean) * (x - f.Mean) / n
+		for _, y := range normalized {
+			diffSum += math.Abs(x - y)
+		}
+	}
+	f.StdDev = math.Sqrt(variance)
+	if f.Mean > 0 {
+		f.Gini = diffSum / (2 * n * n * f.Mean)
+	}
+
+	return f
+}
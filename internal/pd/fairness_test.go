@@ -0,0 +1,76 @@
+package pd_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestNewFairness(t *testing.T) {
+	tests := []struct {
+		name          string
+		counts        map[string]float64
+		fte           map[string]float64
+		wantMean      float64
+		wantStdDev    float64
+		wantMin       float64
+		wantMax       float64
+		wantGini      float64
+		wantTargets   map[string]float64
+		wantDeviation map[string]float64
+	}{
+		{
+			name:          "Equal counts",
+			counts:        map[string]float64{"Alice": 3, "Bob": 3},
+			wantMean:      3,
+			wantMin:       3,
+			wantMax:       3,
+			wantTargets:   map[string]float64{"Alice": 3, "Bob": 3},
+			wantDeviation: map[string]float64{"Alice": 0, "Bob": 0},
+		},
+		{
+			name:          "Unequal counts",
+			counts:        map[string]float64{"Alice": 7.5, "Bob": 1.5},
+			wantMean:      4.5,
+			wantStdDev:    3,
+			wantMin:       1.5,
+			wantMax:       7.5,
+			wantGini:      1.0 / 3,
+			wantTargets:   map[string]float64{"Alice": 4.5, "Bob": 4.5},
+			wantDeviation: map[string]float64{"Alice": 3, "Bob": -3},
+		},
+		{
+			name:          "FTE",
+			counts:        map[string]float64{"Alice": 6, "Bob": 3},
+			fte:           map[string]float64{"Bob": 0.5, "Carol": 0.5},
+			wantMean:      4,
+			wantStdDev:    math.Sqrt(8),
+			wantMin:       0,
+			wantMax:       6,
+			wantGini:      1.0 / 3,
+			wantTargets:   map[string]float64{"Alice": 4.5, "Bob": 2.25, "Carol": 2.25},
+			wantDeviation: map[string]float64{"Alice": 1.5, "Bob": 0.75, "Carol": -2.25},
+		},
+	}
+	approx := func(a, b float64) bool {
+		return math.Abs(a-b) < 1e-9
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := pd.NewFairness(tt.counts, tt.fte)
+			if !approx(f.Mean, tt.wantMean) || !approx(f.StdDev, tt.wantStdDev) || !approx(f.Min, tt.wantMin) || !approx(f.Max, tt.wantMax) || !approx(f.Gini, tt.wantGini) {
+				t.Errorf("NewFairness() = {Mean: %v, StdDev: %v, Min: %v, Max: %v, Gini: %v}, want {Mean: %v, StdDev: %v, Min: %v, Max: %v, Gini: %v}",
+					f.Mean, f.StdDev, f.Min, f.Max, f.Gini, tt.wantMean, tt.wantStdDev, tt.wantMin, tt.wantMax, tt.wantGini)
+			}
+			if len(f.Users) != len(tt.wantTargets) {
+				t.Fatalf("len(f.Users) = %d, want %d", len(f.Users), len(tt.wantTargets))
+			}
+			for _, uf := range f.Users {
+				if !approx(uf.Target, tt.wantTargets[uf.User]) || !approx(uf.Deviation, tt.wantDeviation[uf.User]) {
+					t.Errorf("%s: Target = %v, Deviation = %v, want %v, %v", uf.User, uf.Target, uf.Deviation, tt.wantTargets[uf.User], tt.wantDeviation[uf.User])
+				}
+			}
+		})
+	}
+}
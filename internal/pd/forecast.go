@@ -0,0 +1,68 @@
+package pd
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+type ForecastEntry struct {
+	User string
+	// Actual is the weighted count of shifts ended by now
+	Actual float64
+	// Scheduled is the weighted count of shifts not ended by now and starting before the horizon
+	Scheduled float64
+	// Projected is the sum of Actual, Scheduled, and the share of the remaining count
+	Projected float64
+}
+
+type Forecast struct {
+	// Entries are sorted by user names
+	Entries []*ForecastEntry
+	// Remaining is the expected count of shifts starting at or after the horizon, which are not rendered yet
+	Remaining float64
+}
+
+// NewForecast projects the weighted count of each user at the end of the shifts. Shifts starting before
+// the horizon must have details of the given number of schedules, and the expected count of the rest is
+// distributed to users in proportion to their counts before the horizon.
+func NewForecast(shifts []*Shift, now, horizon time.Time, schedules int) *Forecast {
+	entries := make(map[string]*ForecastEntry)
+	remaining := 0.0
+	for _, shift := range shifts {
+		if !shift.Start.Before(horizon) {
+			remaining += shift.Weight * shift.Fraction() * float64(schedules)
+			continue
+		}
+		for _, details := range shift.Details {
+			for _, detail := range details {
+				e, ok := entries[detail.User]
+				if !ok {
+					e = &ForecastEntry{User: detail.User}
+					entries[detail.User] = e
+				}
+				if shift.End.After(now) {
+					e.Scheduled += detail.Proportion * shift.Weight
+				} else {
+					e.Actual += detail.Proportion * shift.Weight
+				}
+			}
+		}
+	}
+
+	total := 0.0
+	for _, e := range entries {
+		total += e.Actual + e.Scheduled
+	}
+	f := &Forecast{Entries: make([]*ForecastEntry, 0, len(entries)), Remaining: remaining}
+	for _, user := range slices.Sorted(maps.Keys(entries)) {
+		e := entries[user]
+		e.Projected = e.Actual + e.Scheduled
+		if total > 0 {
+			e.Projected += remaining * (e.Actual + e.Scheduled) / total
+		}
+		f.Entries = append(f.Entries, e)
+	}
+
+	return f
+}
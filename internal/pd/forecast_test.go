@@ -0,0 +1,43 @@
+package pd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestNewForecast(t *testing.T) {
+	shifts := make([]*pd.Shift, 8)
+	for i := range shifts {
+		shifts[i] = pd.NewShift(
+			time.Date(2025, time.August, 1+i, 17, 0, 0, 0, time.UTC),
+			time.Date(2025, time.August, 2+i, 5, 0, 0, 0, time.UTC),
+		)
+	}
+	iter := newScheduleEntryIter(t, "primary", time.UTC, []entry{
+		{start: "2025-08-01T17:00:00Z", end: "2025-08-03T05:00:00Z", user: "Alice"},
+		{start: "2025-08-03T17:00:00Z", end: "2025-08-04T05:00:00Z", user: "Bob"},
+		{start: "2025-08-04T17:00:00Z", end: "2025-08-05T05:00:00Z", user: "Alice"},
+	})
+	for _, shift := range shifts[:4] {
+		shift.AddDetails(iter)
+	}
+	shifts[7].Weight = 0
+
+	now := time.Date(2025, time.August, 3, 12, 0, 0, 0, time.UTC)
+	horizon := time.Date(2025, time.August, 5, 0, 0, 0, 0, time.UTC)
+	got := pd.NewForecast(shifts, now, horizon, 1)
+
+	want := &pd.Forecast{
+		Entries: []*pd.ForecastEntry{
+			{User: "Alice", Actual: 2, Scheduled: 1, Projected: 3 + 3*3.0/4},
+			{User: "Bob", Actual: 0, Scheduled: 1, Projected: 1 + 3*1.0/4},
+		},
+		Remaining: 3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewForecast() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,313 @@
+package pd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EffectiveHandoffTimes is a set of handoff times effective from the date
+type EffectiveHandoffTimes struct {
+	From  string
+	Times []string
+}
+
+// maxHandoffInterval is the maximum number of days between days with handoffs
+const maxHandoffInterval = 366
+
+// handoffSchedule determines the handoff times on each day.
+// Each handoff time is in the format "15:04" or "Mon 15:04", where the latter is effective only on the weekday.
+type handoffSchedule struct {
+	// defaultTimes are ordered by effective dates and the first one has no effective date
+	defaultTimes  []*effectiveHandoffTimes
+	weekdayTimes  map[time.Weekday][]string
+	classTimes    map[string][]string
+	dayClassifier *dayClassifier
+	// Handoffs happen only every cycleDays days from cycleAnchor
+	cycleDays   int
+	cycleAnchor time.Time
+	// clocks are the parsed handoff times
+	clocks map[string]time.Time
+}
+
+type effectiveHandoffTimes struct {
+	from  time.Time
+	times []string
+}
+
+func newHandoffSchedule(handoffTimes []string, o *shiftGeneratorOptions, since time.Time, classifier *dayClassifier) (*handoffSchedule, error) {
+	if err := validateHandoffTimes(handoffTimes); err != nil {
+		return nil, err
+	}
+
+	defaultTimes := []*effectiveHandoffTimes{{times: handoffTimes}}
+	for _, e := range o.effectiveHandoffs {
+		from, err := time.Parse(time.DateOnly, e.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid effective date of handoff times: %w", err)
+		}
+		if err := validateHandoffTimes(e.Times); err != nil {
+			return nil, fmt.Errorf("invalid handoff times from %s: %w", e.From, err)
+		}
+		defaultTimes = append(defaultTimes, &effectiveHandoffTimes{from: from, times: e.Times})
+	}
+	slices.SortStableFunc(defaultTimes[1:], func(a, b *effectiveHandoffTimes) int {
+		return a.from.Compare(b.from)
+	})
+	for i := 2; i < len(defaultTimes); i++ {
+		if defaultTimes[i].from.Equal(defaultTimes[i-1].from) {
+			return nil, fmt.Errorf("duplicate effective date of handoff times: %s", defaultTimes[i].from.Format(time.DateOnly))
+		}
+	}
+
+	h := &handoffSchedule{
+		defaultTimes:  defaultTimes,
+		weekdayTimes:  make(map[time.Weekday][]string),
+		classTimes:    make(map[string][]string),
+		dayClassifier: classifier,
+		cycleDays:     1,
+		cycleAnchor:   time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC),
+		clocks:        make(map[string]time.Time),
+	}
+
+	if o.cycleDays < 0 || o.cycleDays > maxHandoffInterval {
+		return nil, fmt.Errorf("handoff cycle days must be between 1 and %d", maxHandoffInterval)
+	}
+	if o.cycleDays > 0 {
+		h.cycleDays = o.cycleDays
+	}
+	if o.cycleAnchor != "" {
+		anchor, err := time.Parse(time.DateOnly, o.cycleAnchor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid anchor date of handoff cycle: %w", err)
+		}
+		h.cycleAnchor = anchor
+	}
+
+	for _, day := range slices.Sorted(maps.Keys(o.dayHandoffs)) {
+		times := o.dayHandoffs[day]
+		if err := validateHandoffTimes(times); err != nil {
+			return nil, fmt.Errorf("invalid handoff times for %q: %w", day, err)
+		}
+		if name, ok := findFold(slices.Collect(maps.Keys(weekdays)), day); ok {
+			h.weekdayTimes[weekdays[name]] = times
+		} else if name, ok := findFold(classifier.names(), day); ok {
+			h.classTimes[name] = times
+		} else if strings.EqualFold(day, nonWorkingDaysClass) {
+			h.classTimes[nonWorkingDaysClass] = times
+		} else {
+			return nil, fmt.Errorf("unknown day %q for handoff times", day)
+		}
+	}
+
+	for _, t := range h.allTimes() {
+		clock, err := time.Parse("15:04", t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid handoff time: %w", err)
+		}
+		h.clocks[t] = clock
+	}
+
+	return h, nil
+}
+
+// findFold returns the name equal to s ignoring case
+func findFold(names []string, s string) (string, bool) {
+	for _, name := range names {
+		if strings.EqualFold(name, s) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func validateHandoffTimes(handoffTimes []string) error {
+	if len(handoffTimes) == 0 {
+		return errors.New("no handoff times provided")
+	}
+	for _, t := range handoffTimes {
+		if fields := strings.Fields(t); len(fields) == 2 {
+			if _, ok := weekdays[fields[0]]; !ok {
+				return fmt.Errorf("invalid weekday of handoff time %q", t)
+			}
+		} else if len(fields) != 1 {
+			return fmt.Errorf("invalid handoff time %q", t)
+		}
+	}
+	if !slices.IsSortedFunc(handoffTimes, CompareHandoffTimes) {
+		return errors.New("handoff times must be sorted")
+	}
+	return nil
+}
+
+// CompareHandoffTimes compares handoff times by time of day ignoring weekdays
+func CompareHandoffTimes(a, b string) int {
+	return strings.Compare(clockTime(a), clockTime(b))
+}
+
+func clockTime(handoffTime string) string {
+	fields := strings.Fields(handoffTime)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// onWeekday returns the handoff times effective on the weekday
+func onWeekday(handoffTimes []string, w time.Weekday) []string {
+	times := make([]string, 0, len(handoffTimes))
+	for _, t := range handoffTimes {
+		if fields := strings.Fields(t); len(fields) == 1 || weekdays[fields[0]] == w {
+			times = append(times, clockTime(t))
+		}
+	}
+	return slices.Compact(times)
+}
+
+// on returns the handoff times on the day, which can be empty
+func (h *handoffSchedule) on(day time.Time) []string {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	if days := int(date.Sub(h.cycleAnchor).Hours() / 24); days%h.cycleDays != 0 {
+		return nil
+	}
+	return onWeekday(h.lookup(day, date), day.Weekday())
+}
+
+// at returns the time of the i-th handoff on the day in the location of the day.
+// The wall clock time is used so that handoffs happen at the same local time across DST transitions.
+func (h *handoffSchedule) at(day time.Time, i int) time.Time {
+	clock := h.clocks[h.on(day)[i]]
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+}
+
+// last returns the day and index of the last handoff at or before t
+func (h *handoffSchedule) last(t time.Time) (time.Time, int, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for range maxHandoffInterval {
+		for i := len(h.on(day)) - 1; i >= 0; i-- {
+			if !h.at(day, i).After(t) {
+				return day, i, true
+			}
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}, 0, false
+}
+
+// following returns the day and index of the handoff following the i-th handoff on the day
+func (h *handoffSchedule) following(day time.Time, i int) (time.Time, int, bool) {
+	if i+1 < len(h.on(day)) {
+		return day, i + 1, true
+	}
+	day, ok := h.next(day.AddDate(0, 0, 1))
+	return day, 0, ok
+}
+
+// first returns the day and index of the first handoff at or after t
+func (h *handoffSchedule) first(t time.Time) (time.Time, int, bool) {
+	day, i, ok := h.last(t)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	if h.at(day, i).Equal(t) {
+		return day, i, true
+	}
+	return h.following(day, i)
+}
+
+// next returns the first day on or after the day that has handoff times
+func (h *handoffSchedule) next(day time.Time) (time.Time, bool) {
+	for range maxHandoffInterval {
+		if len(h.on(day)) > 0 {
+			return day, true
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, false
+}
+
+// lookup returns the handoff times for the day. Handoff times for the day class take precedence over
+// ones for the weekday, which take precedence over the default ones effective on the day.
+func (h *handoffSchedule) lookup(day, date time.Time) []string {
+	class := h.dayClassifier.classify(day)
+	if times, ok := h.classTimes[class]; ok {
+		return times
+	}
+	if class != workingDaysClass {
+		if times, ok := h.classTimes[nonWorkingDaysClass]; ok {
+			return times
+		}
+	}
+	if times, ok := h.weekdayTimes[day.Weekday()]; ok {
+		return times
+	}
+	for i := len(h.defaultTimes) - 1; i > 0; i-- {
+		if !h.defaultTimes[i].from.After(date) {
+			return h.defaultTimes[i].times
+		}
+	}
+	return h.defaultTimes[0].times
+}
+
+// allTimes returns all the handoff times in the schedule in ascending order
+func (h *handoffSchedule) allTimes() []string {
+	times := make([]string, 0)
+	for _, e := range h.defaultTimes {
+		times = append(times, e.times...)
+	}
+	for _, ts := range h.weekdayTimes {
+		times = append(times, ts...)
+	}
+	for _, ts := range h.classTimes {
+		times = append(times, ts...)
+	}
+	for i, t := range times {
+		times[i] = clockTime(t)
+	}
+	slices.Sort(times)
+	return slices.Compact(times)
+}
+
+// shiftRanges returns the time ranges of shifts the schedule can produce in order of start times.
+// A shift across days can start at the last handoff time on a day and end at the first one on any day.
+func (h *handoffSchedule) shiftRanges() []*timeRange {
+	daily := make([][]string, 0)
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		for _, e := range h.defaultTimes {
+			daily = append(daily, onWeekday(e.times, w))
+		}
+		for _, times := range h.classTimes {
+			daily = append(daily, onWeekday(times, w))
+		}
+		if times, ok := h.weekdayTimes[w]; ok {
+			daily = append(daily, onWeekday(times, w))
+		}
+	}
+
+	ranges := make([]*timeRange, 0)
+	add := func(start, end string) {
+		if !slices.ContainsFunc(ranges, func(r *timeRange) bool { return r.start == start && r.end == end }) {
+			ranges = append(ranges, &timeRange{start: start, end: end})
+		}
+	}
+	for _, times := range daily {
+		for i := 1; i < len(times); i++ {
+			add(times[i-1], times[i])
+		}
+	}
+	for _, last := range daily {
+		for _, first := range daily {
+			if len(last) > 0 && len(first) > 0 {
+				add(last[len(last)-1], first[0])
+			}
+		}
+	}
+	slices.SortFunc(ranges, func(a, b *timeRange) int {
+		return cmp.Or(strings.Compare(a.start, b.start), strings.Compare(a.end, b.end))
+	})
+	return ranges
+}
@@ -0,0 +1,215 @@
+package pd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+const (
+	LedgerEntryTypeCount      = "count"
+	LedgerEntryTypeAdjustment = "adjustment"
+)
+
+// LedgerEntry is a line of a ledger in the JSON Lines format
+type LedgerEntry struct {
+	Type string `json:"type"`
+	// Key identifies the count the entry belongs to, such as a profile name, so that counts of different
+	// rotations kept in the same ledger don't affect each other
+	Key string `json:"key,omitempty"`
+	// Since and Until are the period of the count, which are empty for adjustments
+	Since      time.Time `json:"since,omitzero"`
+	Until      time.Time `json:"until,omitzero"`
+	User       string    `json:"user"`
+	Count      float64   `json:"count"`
+	Reason     string    `json:"reason,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Ledger is a history of finalized counts and manual adjustments, which is used to carry over
+// the imbalance of counts to later periods
+type Ledger struct {
+	Entries []*LedgerEntry
+}
+
+// LedgerPeriod is a period whose counts are recorded in a ledger
+type LedgerPeriod struct {
+	Since  time.Time
+	Until  time.Time
+	Counts map[string]float64
+}
+
+// LedgerBalance is the cumulative balance of a user
+type LedgerBalance struct {
+	User string
+	// Counted is the sum of the recorded counts
+	Counted float64
+	// Adjusted is the sum of the adjustments
+	Adjusted float64
+	// Expected is the sum of the mean counts of the periods in which the user is recorded
+	Expected float64
+	// CarryOver is the count the user has done more than expected, which is negative if the user has done less
+	CarryOver float64
+}
+
+// ReadLedger reads a ledger in the JSON Lines format
+func ReadLedger(r io.Reader) (*Ledger, error) {
+	l := &Ledger{}
+	scanner := bufio.NewScanner(r)
+	for i := 1; scanner.Scan(); i++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid ledger entry at line %d: %w", i, err)
+		}
+		if e.Type != LedgerEntryTypeCount && e.Type != LedgerEntryTypeAdjustment {
+			return nil, fmt.Errorf("invalid ledger entry at line %d: unknown type %q", i, e.Type)
+		}
+		l.Entries = append(l.Entries, &e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write writes the ledger in the JSON Lines format
+func (l *Ledger) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range l.Entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record records the counts of the period for the key. The counts recorded for the same period are replaced,
+// and the period must not overlap other periods recorded for the key.
+func (l *Ledger) Record(key string, since, until time.Time, counts map[string]float64, now time.Time) error {
+	if !since.Before(until) {
+		return fmt.Errorf("until must be after since: %s - %s", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	for _, p := range l.Periods(key) {
+		if p.Since.Equal(since) && p.Until.Equal(until) {
+			continue
+		}
+		if p.Since.Before(until) && since.Before(p.Until) {
+			return fmt.Errorf("period %s - %s overlaps the recorded period %s - %s",
+				since.Format(time.RFC3339), until.Format(time.RFC3339), p.Since.Format(time.RFC3339), p.Until.Format(time.RFC3339))
+		}
+	}
+
+	l.Entries = slices.DeleteFunc(l.Entries, func(e *LedgerEntry) bool {
+		return e.Type == LedgerEntryTypeCount && e.Key == key && e.Since.Equal(since) && e.Until.Equal(until)
+	})
+	for _, user := range slices.Sorted(maps.Keys(counts)) {
+		l.Entries = append(l.Entries, &LedgerEntry{
+			Type:       LedgerEntryTypeCount,
+			Key:        key,
+			Since:      since,
+			Until:      until,
+			User:       user,
+			Count:      counts[user],
+			RecordedAt: now,
+		})
+	}
+	return nil
+}
+
+// Adjust records a manual adjustment of the count of the user for the key
+func (l *Ledger) Adjust(key, user string, count float64, reason string, now time.Time) error {
+	if user == "" {
+		return errors.New("user of the adjustment is required")
+	}
+	if reason == "" {
+		return errors.New("reason of the adjustment is required")
+	}
+	l.Entries = append(l.Entries, &LedgerEntry{
+		Type:       LedgerEntryTypeAdjustment,
+		Key:        key,
+		User:       user,
+		Count:      count,
+		Reason:     reason,
+		RecordedAt: now,
+	})
+	return nil
+}
+
+// Periods returns the periods recorded for the key in chronological order
+func (l *Ledger) Periods(key string) []*LedgerPeriod {
+	periods := make([]*LedgerPeriod, 0)
+	for _, e := range l.Entries {
+		if e.Type != LedgerEntryTypeCount || e.Key != key {
+			continue
+		}
+		i := slices.IndexFunc(periods, func(p *LedgerPeriod) bool {
+			return p.Since.Equal(e.Since) && p.Until.Equal(e.Until)
+		})
+		if i == -1 {
+			periods = append(periods, &LedgerPeriod{Since: e.Since, Until: e.Until, Counts: make(map[string]float64)})
+			i = len(periods) - 1
+		}
+		periods[i].Counts[e.User] += e.Count
+	}
+	slices.SortFunc(periods, func(a, b *LedgerPeriod) int {
+		return a.Since.Compare(b.Since)
+	})
+	return periods
+}
+
+// Adjustments returns the manual adjustments for the key in order of recording
+func (l *Ledger) Adjustments(key string) []*LedgerEntry {
+	adjustments := make([]*LedgerEntry, 0)
+	for _, e := range l.Entries {
+		if e.Type == LedgerEntryTypeAdjustment && e.Key == key {
+			adjustments = append(adjustments, e)
+		}
+	}
+	return adjustments
+}
+
+// Balances returns the cumulative balances for the key sorted by user names. The expected count of each period is
+// the mean of the counts of the users recorded in the period.
+func (l *Ledger) Balances(key string) []*LedgerBalance {
+	balances := make(map[string]*LedgerBalance)
+	balanceOf := func(user string) *LedgerBalance {
+		b, ok := balances[user]
+		if !ok {
+			b = &LedgerBalance{User: user}
+			balances[user] = b
+		}
+		return b
+	}
+
+	for _, p := range l.Periods(key) {
+		total := 0.0
+		for _, count := range p.Counts {
+			total += count
+		}
+		mean := total / float64(len(p.Counts))
+		for user, count := range p.Counts {
+			b := balanceOf(user)
+			b.Counted += count
+			b.Expected += mean
+		}
+	}
+	for _, e := range l.Adjustments(key) {
+		balanceOf(e.User).Adjusted += e.Count
+	}
+
+	result := make([]*LedgerBalance, 0, len(balances))
+	for _, user := range slices.Sorted(maps.Keys(balances)) {
+		b := balances[user]
+		b.CarryOver = b.Counted + b.Adjusted - b.Expected
+		result = append(result, b)
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package pd_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestLedger_Record(t *testing.T) {
+	q1 := [2]time.Time{time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)}
+	q2 := [2]time.Time{time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)}
+	now := time.Date(2025, time.October, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		key         string
+		since       time.Time
+		until       time.Time
+		counts      map[string]float64
+		wantPeriods []*pd.LedgerPeriod
+		wantErr     string
+	}{
+		{
+			name:   "New period",
+			key:    "sre",
+			since:  q2[0],
+			until:  q2[1],
+			counts: map[string]float64{"Bob": 2, "Alice": 4},
+			wantPeriods: []*pd.LedgerPeriod{
+				{Since: q1[0], Until: q1[1], Counts: map[string]float64{"Alice": 6, "Bob": 3}},
+				{Since: q2[0], Until: q2[1], Counts: map[string]float64{"Alice": 4, "Bob": 2}},
+			},
+		},
+		{
+			name:   "Same period",
+			key:    "sre",
+			since:  q1[0],
+			until:  q1[1],
+			counts: map[string]float64{"Alice": 5},
+			wantPeriods: []*pd.LedgerPeriod{
+				{Since: q1[0], Until: q1[1], Counts: map[string]float64{"Alice": 5}},
+			},
+		},
+		{
+			name:    "Overlapping period",
+			key:     "sre",
+			since:   time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			until:   q2[1],
+			counts:  map[string]float64{"Alice": 5},
+			wantErr: "period 2025-06-01T00:00:00Z - 2025-10-01T00:00:00Z overlaps the recorded period 2025-04-01T00:00:00Z - 2025-07-01T00:00:00Z",
+		},
+		{
+			name:   "Overlapping period of another key",
+			key:    "support",
+			since:  time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+			until:  q2[1],
+			counts: map[string]float64{"Carol": 5},
+			wantPeriods: []*pd.LedgerPeriod{
+				{Since: q1[0], Until: q1[1], Counts: map[string]float64{"Alice": 6, "Bob": 3}},
+			},
+		},
+		{
+			name:    "Invalid period",
+			key:     "sre",
+			since:   q2[1],
+			until:   q2[0],
+			wantErr: "until must be after since: 2025-10-01T00:00:00Z - 2025-07-01T00:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &pd.Ledger{}
+			if err := l.Record("sre", q1[0], q1[1], map[string]float64{"Alice": 6, "Bob": 3}, now); err != nil {
+				t.Fatal(err)
+			}
+
+			err := l.Record(tt.key, tt.since, tt.until, tt.counts, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Record() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Record() = %v, want nil", err)
+			}
+			if got := l.Periods("sre"); !reflect.DeepEqual(got, tt.wantPeriods) {
+				t.Errorf("Periods() = %v, want %v", got, tt.wantPeriods)
+			}
+		})
+	}
+}
+
+func TestLedger_Balances(t *testing.T) {
+	l := &pd.Ledger{}
+	now := time.Date(2025, time.October, 2, 0, 0, 0, 0, time.UTC)
+	if err := l.Record("sre", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), map[string]float64{"Alice": 6, "Bob": 3}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record("sre", time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), map[string]float64{"Alice": 2, "Bob": 4, "Carol": 3}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record("support", time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC), map[string]float64{"Alice": 10, "Dave": 2}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Adjust("sre", "Bob", 1, "Handled an incident off schedule", now); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Adjust("support", "Carol", 1, "Handled an incident off schedule", now); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*pd.LedgerBalance{
+		{User: "Alice", Counted: 8, Expected: 7.5, CarryOver: 0.5},
+		{User: "Bob", Counted: 7, Adjusted: 1, Expected: 7.5, CarryOver: 0.5},
+		{User: "Carol", Counted: 3, Expected: 3, CarryOver: 0},
+	}
+	if got := l.Balances("sre"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Balances() = %v, want %v", got, want)
+	}
+}
+
+func TestLedger_Adjust(t *testing.T) {
+	now := time.Date(2025, time.October, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		user    string
+		reason  string
+		wantErr string
+	}{
+		{name: "Valid", user: "Alice", reason: "Covered a shift off schedule"},
+		{name: "No user", reason: "Covered a shift off schedule", wantErr: "user of the adjustment is required"},
+		{name: "No reason", user: "Alice", wantErr: "reason of the adjustment is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &pd.Ledger{}
+			err := l.Adjust("sre", tt.user, 1, tt.reason, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Adjust() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Adjust() = %v, want nil", err)
+			}
+			want := []*pd.LedgerEntry{{Type: pd.LedgerEntryTypeAdjustment, Key: "sre", User: tt.user, Count: 1, Reason: tt.reason, RecordedAt: now}}
+			if got := l.Adjustments("sre"); !reflect.DeepEqual(got, want) {
+				t.Errorf("Adjustments() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadLedger(t *testing.T) {
+	input := `{"type":"count","key":"sre","since":"2025-04-01T00:00:00Z","until":"2025-07-01T00:00:00Z","user":"Alice","count":6,"recorded_at":"2025-07-02T00:00:00Z"}
+{"type":"adjustment","key":"sre","user":"Alice","count":-1,"reason":"Swapped off the record","recorded_at":"2025-07-03T00:00:00Z"}
+`
+	l, err := pd.ReadLedger(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadLedger() = %v, want nil", err)
+	}
+
+	var b bytes.Buffer
+	if err := l.Write(&b); err != nil {
+		t.Fatalf("Write() = %v, want nil", err)
+	}
+	if b.String() != input {
+		t.Errorf("b.String() = %v, want %v", b.String(), input)
+	}
+
+	if _, err := pd.ReadLedger(strings.NewReader(`{"type":"unknown"}`)); err == nil || err.Error() != `invalid ledger entry at line 1: unknown type "unknown"` {
+		t.Errorf("ReadLedger() = %v, want an error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package pd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Override is an override of a schedule to be created
+type Override struct {
+	Start  time.Time
+	End    time.Time
+	UserID string
+	// User is the name of the user, which is only used for display
+	User string
+}
+
+// OverrideDiff is the change of a schedule made by an override
+type OverrideDiff struct {
+	Override *Override
+	// Replaced is the on-call users in the rendered schedule replaced by the override
+	Replaced []ShiftDetail
+}
+
+// DiffOverrides validates overrides against the rendered schedule of iter and returns the changes they make
+// in order of their start times
+func DiffOverrides(overrides []*Override, iter *ScheduleEntryIter) ([]*OverrideDiff, error) {
+	overrides = slices.SortedFunc(slices.Values(overrides), func(a, b *Override) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	diffs := make([]*OverrideDiff, len(overrides))
+	for i, o := range overrides {
+		if !o.Start.Before(o.End) {
+			return nil, fmt.Errorf("end of the override must be after start: %s - %s", o.Start.Format(time.RFC3339), o.End.Format(time.RFC3339))
+		}
+		if o.UserID == "" {
+			return nil, fmt.Errorf("user ID of the override from %s to %s is required", o.Start.Format(time.RFC3339), o.End.Format(time.RFC3339))
+		}
+		if i > 0 && overrides[i-1].End.After(o.Start) {
+			prev := overrides[i-1]
+			return nil, fmt.Errorf("overrides overlap: %s - %s and %s - %s",
+				prev.Start.Format(time.RFC3339), prev.End.Format(time.RFC3339), o.Start.Format(time.RFC3339), o.End.Format(time.RFC3339))
+		}
+
+		shift := NewShift(o.Start, o.End)
+		shift.AddDetails(iter)
+		replaced := shift.Details[iter.scheduleName]
+		if len(replaced) == 1 && replaced[0].UserID == o.UserID && replaced[0].Start.Equal(o.Start) && replaced[0].End.Equal(o.End) {
+			return nil, fmt.Errorf("user %s is already on call from %s to %s", o.UserID, o.Start.Format(time.RFC3339), o.End.Format(time.RFC3339))
+		}
+		diffs[i] = &OverrideDiff{Override: o, Replaced: replaced}
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,105 @@
+package pd_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestDiffOverrides(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.August, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		overrides []*pd.Override
+		want      [][]string
+		wantErr   string
+	}{
+		{
+			name: "Replace users",
+			overrides: []*pd.Override{
+				{Start: at(2, 5), End: at(2, 17), UserID: "PBOB123"},
+				{Start: at(1, 17), End: at(2, 5), UserID: "PBOB123"},
+			},
+			want: [][]string{
+				{"Alice"},
+				{"Alice", "Carol"},
+			},
+		},
+		{
+			name: "Invalid period",
+			overrides: []*pd.Override{
+				{Start: at(2, 5), End: at(2, 5), UserID: "PBOB123"},
+			},
+			wantErr: "end of the override must be after start: 2025-08-02T05:00:00Z - 2025-08-02T05:00:00Z",
+		},
+		{
+			name: "Missing user ID",
+			overrides: []*pd.Override{
+				{Start: at(2, 5), End: at(2, 17)},
+			},
+			wantErr: "user ID of the override from 2025-08-02T05:00:00Z to 2025-08-02T17:00:00Z is required",
+		},
+		{
+			name: "Overlap",
+			overrides: []*pd.Override{
+				{Start: at(1, 17), End: at(2, 17), UserID: "PBOB123"},
+				{Start: at(2, 5), End: at(3, 5), UserID: "PBOB123"},
+			},
+			wantErr: "overrides overlap: 2025-08-01T17:00:00Z - 2025-08-02T17:00:00Z and 2025-08-02T05:00:00Z - 2025-08-03T05:00:00Z",
+		},
+		{
+			name: "Already on call",
+			overrides: []*pd.Override{
+				{Start: at(1, 17), End: at(2, 5), UserID: "PALICE1"},
+			},
+			wantErr: "user PALICE1 is already on call from 2025-08-01T17:00:00Z to 2025-08-02T05:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := pd.NewScheduleEntryIter("primary", time.UTC, []pagerduty.RenderedScheduleEntry{
+				{
+					Start: "2025-08-01T05:00:00Z",
+					End:   "2025-08-02T09:00:00Z",
+					User:  pagerduty.APIObject{ID: "PALICE1", Summary: "Alice"},
+				},
+				{
+					Start: "2025-08-02T09:00:00Z",
+					End:   "2025-08-03T05:00:00Z",
+					User:  pagerduty.APIObject{ID: "PCAROL1", Summary: "Carol"},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diffs, err := pd.DiffOverrides(tt.overrides, iter)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([][]string, len(diffs))
+			for i, diff := range diffs {
+				got[i] = make([]string, len(diff.Replaced))
+				for j, detail := range diff.Replaced {
+					got[i][j] = detail.User
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package pd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	monthPeriodRegexp     = regexp.MustCompile(`\A(\d{4})-(\d{2})\z`)
+	quarterPeriodRegexp   = regexp.MustCompile(`\A(\d{4})-Q([1-4])\z`)
+	halfPeriodRegexp      = regexp.MustCompile(`\A(\d{4})-H([12])\z`)
+	fiscalPeriodRegexp    = regexp.MustCompile(`\Afiscal:(\d{4})\z`)
+	lastNDaysPeriodRegexp = regexp.MustCompile(`\Alast-(\d+)-days\z`)
+)
+
+// ParsePeriod returns since and until in the format "2006-01-02" for the period such as "last-month",
+// "this-month", "2025-07", "2025-Q3", "2025-H1", "fiscal:2025", and "last-7-days".
+// Relative periods are resolved based on now, and a fiscal year is named after the year in which it starts.
+func ParsePeriod(period string, now time.Time, fiscalYearStart time.Month) (string, string, error) {
+	if fiscalYearStart < time.January || fiscalYearStart > time.December {
+		return "", "", fmt.Errorf("invalid start month of fiscal year: %d", fiscalYearStart)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	thisMonth := today.AddDate(0, 0, 1-today.Day())
+
+	var since, until time.Time
+	switch {
+	case period == "last-month":
+		since, until = thisMonth.AddDate(0, -1, 0), thisMonth
+	case period == "this-month":
+		since, until = thisMonth, thisMonth.AddDate(0, 1, 0)
+	case monthPeriodRegexp.MatchString(period):
+		m := monthPeriodRegexp.FindStringSubmatch(period)
+		month := atoi(m[2])
+		if month < 1 || month > 12 {
+			return "", "", fmt.Errorf("invalid month in period %q", period)
+		}
+		since = time.Date(atoi(m[1]), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		until = since.AddDate(0, 1, 0)
+	case quarterPeriodRegexp.MatchString(period):
+		m := quarterPeriodRegexp.FindStringSubmatch(period)
+		since = time.Date(atoi(m[1]), time.Month(3*atoi(m[2])-2), 1, 0, 0, 0, 0, time.UTC)
+		until = since.AddDate(0, 3, 0)
+	case halfPeriodRegexp.MatchString(period):
+		m := halfPeriodRegexp.FindStringSubmatch(period)
+		since = time.Date(atoi(m[1]), time.Month(6*atoi(m[2])-5), 1, 0, 0, 0, 0, time.UTC)
+		until = since.AddDate(0, 6, 0)
+	case fiscalPeriodRegexp.MatchString(period):
+		m := fiscalPeriodRegexp.FindStringSubmatch(period)
+		since = time.Date(atoi(m[1]), fiscalYearStart, 1, 0, 0, 0, 0, time.UTC)
+		until = since.AddDate(1, 0, 0)
+	case lastNDaysPeriodRegexp.MatchString(period):
+		m := lastNDaysPeriodRegexp.FindStringSubmatch(period)
+		n := atoi(m[1])
+		if n == 0 {
+			return "", "", fmt.Errorf("invalid number of days in period %q", period)
+		}
+		since, until = today.AddDate(0, 0, -n), today
+	default:
+		return "", "", fmt.Errorf("unknown period %q", period)
+	}
+
+	return since.Format(time.DateOnly), until.Format(time.DateOnly), nil
+}
+
+// atoi converts a string consisting of digits to int
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
@@ -0,0 +1,112 @@
+package pd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestParsePeriod(t *testing.T) {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2025-08-01 in JST but 2025-07-31 in UTC
+	now := time.Date(2025, time.August, 1, 8, 0, 0, 0, jst)
+
+	tests := []struct {
+		name            string
+		period          string
+		fiscalYearStart time.Month
+		wantSince       string
+		wantUntil       string
+		wantErr         string
+	}{
+		{
+			name:            "Last month",
+			period:          "last-month",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-07-01",
+			wantUntil:       "2025-08-01",
+		},
+		{
+			name:            "This month",
+			period:          "this-month",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-08-01",
+			wantUntil:       "2025-09-01",
+		},
+		{
+			name:            "Month",
+			period:          "2025-12",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-12-01",
+			wantUntil:       "2026-01-01",
+		},
+		{
+			name:            "Quarter",
+			period:          "2025-Q3",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-07-01",
+			wantUntil:       "2025-10-01",
+		},
+		{
+			name:            "Half",
+			period:          "2025-H1",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-01-01",
+			wantUntil:       "2025-07-01",
+		},
+		{
+			name:            "Fiscal year",
+			period:          "fiscal:2025",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-04-01",
+			wantUntil:       "2026-04-01",
+		},
+		{
+			name:            "Last N days",
+			period:          "last-7-days",
+			fiscalYearStart: time.April,
+			wantSince:       "2025-07-25",
+			wantUntil:       "2025-08-01",
+		},
+		{
+			name:            "Invalid month",
+			period:          "2025-13",
+			fiscalYearStart: time.April,
+			wantErr:         `invalid month in period "2025-13"`,
+		},
+		{
+			name:            "Invalid start month of fiscal year",
+			period:          "fiscal:2025",
+			fiscalYearStart: 13,
+			wantErr:         "invalid start month of fiscal year: 13",
+		},
+		{
+			name:            "Unknown period",
+			period:          "next-month",
+			fiscalYearStart: time.April,
+			wantErr:         `unknown period "next-month"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := pd.ParsePeriod(tt.period, now, tt.fiscalYearStart)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if since != tt.wantSince || until != tt.wantUntil {
+				t.Errorf("ParsePeriod() = (%v, %v), want (%v, %v)", since, until, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package pd
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// Participant is a person to whom shifts are assigned
+type Participant struct {
+	Name   string
+	UserID string
+	// UnavailableDates are dates in the format "2006-01-02" on which the participant cannot take shifts
+	UnavailableDates []string
+}
+
+type PlanConstraints struct {
+	// MaxConsecutiveNights is the maximum number of night shifts on consecutive days, where a night shift
+	// spans midnight. Zero means no limit.
+	MaxConsecutiveNights int
+	// MinRest is the minimum rest between shifts assigned to the same participant
+	MinRest time.Duration
+}
+
+type Assignment struct {
+	Shift       *Shift
+	Participant *Participant
+	// Weight is the weighted count of the shift
+	Weight float64
+}
+
+// participantState is the state of a participant while planning
+type participantState struct {
+	participant       *Participant
+	unavailable       map[string]bool
+	load              float64
+	hours             time.Duration
+	lastEnd           time.Time
+	lastNightStart    time.Time
+	consecutiveNights int
+}
+
+// Plan assigns shifts to participants one by one so that the weighted shift counts are balanced.
+// Each shift is assigned to the available participant with the smallest weighted count,
+// and then with the shortest assigned hours.
+func Plan(shifts iter.Seq[*Shift], participants []*Participant, constraints PlanConstraints) ([]*Assignment, error) {
+	if len(participants) == 0 {
+		return nil, errors.New("no participants provided")
+	}
+
+	states := make([]*participantState, len(participants))
+	for i, p := range participants {
+		unavailable, err := newUnavailableDates(p)
+		if err != nil {
+			return nil, err
+		}
+		states[i] = &participantState{participant: p, unavailable: unavailable}
+	}
+
+	assignments := make([]*Assignment, 0)
+	for shift := range shifts {
+		var chosen *participantState
+		for _, s := range states {
+			if !s.canTake(shift, constraints) {
+				continue
+			}
+			if chosen == nil || s.load < chosen.load || (s.load == chosen.load && s.hours < chosen.hours) {
+				chosen = s
+			}
+		}
+		if chosen == nil {
+			return nil, fmt.Errorf("no participant can take the shift from %s to %s", shift.Start.Format(time.RFC3339), shift.End.Format(time.RFC3339))
+		}
+
+		weight := shift.Weight * shift.Fraction()
+		chosen.assign(shift, weight)
+		assignments = append(assignments, &Assignment{Shift: shift, Participant: chosen.participant, Weight: weight})
+	}
+
+	return assignments, nil
+}
+
+func isNightShift(shift *Shift) bool {
+	return shift.Start.Format(time.DateOnly) != shift.End.Add(-time.Nanosecond).Format(time.DateOnly)
+}
+
+// newUnavailableDates returns the set of the unavailable dates of p
+func newUnavailableDates(p *Participant) (map[string]bool, error) {
+	unavailable := make(map[string]bool, len(p.UnavailableDates))
+	for _, d := range p.UnavailableDates {
+		if _, err := time.Parse(time.DateOnly, d); err != nil {
+			return nil, fmt.Errorf("invalid unavailable date of %s: %w", p.Name, err)
+		}
+		unavailable[d] = true
+	}
+	return unavailable, nil
+}
+
+// overlapDates reports whether the shift overlaps any of the dates
+func overlapDates(dates map[string]bool, shift *Shift) bool {
+	for d := shift.Start; d.Before(shift.End); d = d.AddDate(0, 0, 1) {
+		if dates[d.Format(time.DateOnly)] {
+			return true
+		}
+	}
+	return dates[shift.End.Add(-time.Nanosecond).Format(time.DateOnly)]
+}
+
+func (s *participantState) canTake(shift *Shift, constraints PlanConstraints) bool {
+	if overlapDates(s.unavailable, shift) {
+		return false
+	}
+
+	if !s.lastEnd.IsZero() && shift.Start.Sub(s.lastEnd) < constraints.MinRest {
+		return false
+	}
+
+	if constraints.MaxConsecutiveNights > 0 && isNightShift(shift) && s.nextConsecutiveNights(shift) > constraints.MaxConsecutiveNights {
+		return false
+	}
+
+	return true
+}
+
+func (s *participantState) nextConsecutiveNights(shift *Shift) int {
+	if !s.lastNightStart.IsZero() && s.lastNightStart.AddDate(0, 0, 1).Format(time.DateOnly) == shift.Start.Format(time.DateOnly) {
+		return s.consecutiveNights + 1
+	}
+	return 1
+}
+
+func (s *participantState) assign(shift *Shift, weight float64) {
+	s.load += weight
+	s.hours += shift.End.Sub(shift.Start)
+	s.lastEnd = shift.End
+	if isNightShift(shift) {
+		s.consecutiveNights = s.nextConsecutiveNights(shift)
+		s.lastNightStart = shift.Start
+	}
+}
@@ -0,0 +1,101 @@
+package pd_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abicky/pd-shift/internal/pd"
+)
+
+func TestPlan(t *testing.T) {
+	nightShifts := func(days int) []*pd.Shift {
+		shifts := make([]*pd.Shift, days)
+		for i := range shifts {
+			shifts[i] = pd.NewShift(
+				time.Date(2025, time.August, 1+i, 17, 0, 0, 0, time.UTC),
+				time.Date(2025, time.August, 2+i, 5, 0, 0, 0, time.UTC),
+			)
+		}
+		return shifts
+	}
+
+	tests := []struct {
+		name         string
+		shifts       []*pd.Shift
+		participants []*pd.Participant
+		constraints  pd.PlanConstraints
+		want         []string
+		wantErr      string
+	}{
+		{
+			name: "Balance weighted counts",
+			shifts: []*pd.Shift{
+				pd.NewShift(
+					time.Date(2025, time.August, 1, 5, 0, 0, 0, time.UTC),
+					time.Date(2025, time.August, 1, 17, 0, 0, 0, time.UTC),
+				),
+				withWeight(pd.NewShift(
+					time.Date(2025, time.August, 1, 17, 0, 0, 0, time.UTC),
+					time.Date(2025, time.August, 2, 5, 0, 0, 0, time.UTC),
+				), 0),
+				pd.NewShift(
+					time.Date(2025, time.August, 2, 5, 0, 0, 0, time.UTC),
+					time.Date(2025, time.August, 2, 17, 0, 0, 0, time.UTC),
+				),
+				pd.NewShift(
+					time.Date(2025, time.August, 2, 17, 0, 0, 0, time.UTC),
+					time.Date(2025, time.August, 3, 5, 0, 0, 0, time.UTC),
+				),
+			},
+			participants: []*pd.Participant{{Name: "Alice"}, {Name: "Bob"}},
+			want:         []string{"Alice", "Bob", "Bob", "Alice"},
+		},
+		{
+			name:   "Unavailable dates",
+			shifts: nightShifts(3),
+			participants: []*pd.Participant{
+				{Name: "Alice", UnavailableDates: []string{"2025-08-02"}},
+				{Name: "Bob"},
+			},
+			want: []string{"Bob", "Bob", "Alice"},
+		},
+		{
+			name:         "Max consecutive nights",
+			shifts:       nightShifts(3),
+			participants: []*pd.Participant{{Name: "Alice"}, {Name: "Bob", UnavailableDates: []string{"2025-08-02", "2025-08-03"}}},
+			constraints:  pd.PlanConstraints{MaxConsecutiveNights: 2},
+			wantErr:      "no participant can take the shift from 2025-08-03T17:00:00Z to 2025-08-04T05:00:00Z",
+		},
+		{
+			name:         "Min rest",
+			shifts:       nightShifts(3),
+			participants: []*pd.Participant{{Name: "Alice"}, {Name: "Bob"}},
+			constraints:  pd.PlanConstraints{MinRest: 48 * time.Hour},
+			wantErr:      "no participant can take the shift from 2025-08-03T17:00:00Z to 2025-08-04T05:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignments, err := pd.Plan(slices.Values(tt.shifts), tt.participants, tt.constraints)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := make([]string, len(assignments))
+			for i, a := range assignments {
+				names[i] = a.Participant.Name
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
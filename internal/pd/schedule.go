@@ -14,9 +14,10 @@ type ScheduleEntryIter struct {
 }
 
 type ScheduleEntry struct {
-	Start time.Time
-	End   time.Time
-	User  string
+	Start  time.Time
+	End    time.Time
+	User   string
+	UserID string
 }
 
 func NewScheduleEntryIter(scheduleName string, tz *time.Location, rsEntries []pagerduty.RenderedScheduleEntry) (*ScheduleEntryIter, error) {
@@ -31,9 +32,10 @@ func NewScheduleEntryIter(scheduleName string, tz *time.Location, rsEntries []pa
 			return nil, err
 		}
 		entries[i] = &ScheduleEntry{
-			Start: s,
-			End:   e,
-			User:  entry.User.Summary,
+			Start:  s,
+			End:    e,
+			User:   entry.User.Summary,
+			UserID: entry.User.ID,
 		}
 	}
 
@@ -8,12 +8,21 @@ type Shift struct {
 	Start   time.Time
 	End     time.Time
 	Details map[string][]ShiftDetail
+	// Weight is the proportion of the shift to be counted
+	Weight float64
+	// ExcludedBy is the exclude condition that excluded the shift entirely or partly
+	ExcludedBy string
 
 	duration time.Duration
+	// countStart and countEnd are the boundaries of the period to be counted,
+	// which differ from Start and End if the shift is clipped
+	countStart time.Time
+	countEnd   time.Time
 }
 
 type ShiftDetail struct {
 	User       string
+	UserID     string
 	Start      time.Time
 	End        time.Time
 	Proportion float64
@@ -21,10 +30,37 @@ type ShiftDetail struct {
 
 func NewShift(start, end time.Time) *Shift {
 	return &Shift{
-		Start:    start,
-		End:      end,
-		Details:  make(map[string][]ShiftDetail),
-		duration: end.Sub(start),
+		Start:      start,
+		End:        end,
+		Details:    make(map[string][]ShiftDetail),
+		Weight:     1,
+		duration:   end.Sub(start),
+		countStart: start,
+		countEnd:   end,
+	}
+}
+
+// Clipped reports whether the shift is clipped at the boundaries of the period
+func (s *Shift) Clipped() bool {
+	return !s.countStart.Equal(s.Start) || !s.countEnd.Equal(s.End)
+}
+
+// CountedPeriod returns the start and end of the period of the shift to be counted
+func (s *Shift) CountedPeriod() (time.Time, time.Time) {
+	return s.countStart, s.countEnd
+}
+
+// Fraction returns the proportion of the counted period to the whole shift
+func (s *Shift) Fraction() float64 {
+	return float64(s.countEnd.Sub(s.countStart)) / float64(s.duration)
+}
+
+func (s *Shift) clip(since, until time.Time) {
+	if s.countStart.Before(since) {
+		s.countStart = since
+	}
+	if s.countEnd.After(until) {
+		s.countEnd = until
 	}
 }
 
@@ -41,13 +77,13 @@ func (s *Shift) AddDetails(iter *ScheduleEntryIter) {
 		//    |--------------------|
 		// 4. entry.Start                              entry.End
 		//    |----------------------------------------|
-		if !s.End.After(entry.Start) { // 1
+		if !s.countEnd.After(entry.Start) { // 1
 			break
-		} else if !entry.End.After(s.Start) { // 2
+		} else if !entry.End.After(s.countStart) { // 2
 			entry = iter.Next()
 		} else {
 			s.addDetail(iter.scheduleName, entry)
-			if !entry.End.After(s.End) { // 3
+			if !entry.End.After(s.countEnd) { // 3
 				entry = iter.Next()
 			} else { // 4
 				break
@@ -62,7 +98,7 @@ func (s *Shift) addDetail(name string, e *ScheduleEntry) {
 	//      e.Start        e.End
 	//      |--------------|
 	// -> start = e.Start
-	start := s.Start
+	start := s.countStart
 	if e.Start.After(start) {
 		start = e.Start
 	}
@@ -72,13 +108,14 @@ func (s *Shift) addDetail(name string, e *ScheduleEntry) {
 	// e.Start        e.End
 	// |--------------|
 	// -> end = e.End
-	end := s.End
+	end := s.countEnd
 	if e.End.Before(end) {
 		end = e.End
 	}
 
 	s.Details[name] = append(s.Details[name], ShiftDetail{
 		User:       e.User,
+		UserID:     e.UserID,
 		Start:      start,
 		End:        end,
 		Proportion: float64(end.Sub(start)) / float64(s.duration),
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"regexp"
 	"slices"
 	"strings"
@@ -33,44 +34,111 @@ var (
 )
 
 type ShiftGenerator struct {
-	since             time.Time
-	until             time.Time
-	current           time.Time
+	since time.Time
+	until time.Time
+	// start and end are the boundaries of the period to be counted
+	start   time.Time
+	current time.Time
+	// day and index identify the handoff time of current
+	day               time.Time
 	index             int
-	shiftDurations    []time.Duration
+	end               time.Time
+	handoffSchedule   *handoffSchedule
 	includeConditions []includeCondition
-	nonWorkingDaySet  *nonWorkingDaySet
+	excludeConditions []*excludeCondition
+	dayClassifier     *dayClassifier
+	dayTypeAnchor     dayTypeAnchor
+	shiftLabels       []*shiftLabel
+	clip              bool
 }
 
+type ShiftGeneratorOption func(*shiftGeneratorOptions)
+
+type shiftGeneratorOptions struct {
+	dayTypeAnchor     string
+	dayClasses        []DayClass
+	exclude           []string
+	selectExpr        string
+	shiftLabels       map[string]string
+	dayHandoffs       map[string][]string
+	effectiveHandoffs []EffectiveHandoffTimes
+	cycleDays         int
+	cycleAnchor       string
+	clip              bool
+}
+
+// dayTypeAnchor determines which part of a shift is used to decide whether the shift is on a non-working day
+type dayTypeAnchor string
+
+const (
+	dayTypeAnchorStart    dayTypeAnchor = "start"
+	dayTypeAnchorEnd      dayTypeAnchor = "end"
+	dayTypeAnchorMajority dayTypeAnchor = "majority"
+	dayTypeAnchorSplit    dayTypeAnchor = "split"
+)
+
 type includeCondition interface {
-	match(shift *Shift) bool
+	match(shift *Shift, class string) bool
+}
+
+// excludeCondition is an include condition used to exclude shifts
+type excludeCondition struct {
+	includeCondition
+	text string
 }
 
 type workingDaysIncludeCondition struct {
-	timeRanges       []*timeRange
-	nonWorkingDaySet *nonWorkingDaySet
+	timeRanges []*timeRange
 }
 
 var _ includeCondition = (*workingDaysIncludeCondition)(nil)
 
 type nonWorkingDaysIncludeCondition struct {
-	timeRanges       []*timeRange
-	nonWorkingDaySet *nonWorkingDaySet
+	timeRanges []*timeRange
 }
 
 var _ includeCondition = (*nonWorkingDaysIncludeCondition)(nil)
 
+type dayClassIncludeCondition struct {
+	class      string
+	timeRanges []*timeRange
+}
+
+var _ includeCondition = (*dayClassIncludeCondition)(nil)
+
+type weekdayIncludeCondition struct {
+	weekday    time.Weekday
+	timeRanges []*timeRange
+}
+
+var _ includeCondition = (*weekdayIncludeCondition)(nil)
+
+type dateIncludeCondition struct {
+	year       int
+	month      time.Month
+	day        int
+	timeRanges []*timeRange
+}
+
+var _ includeCondition = (*dateIncludeCondition)(nil)
+
 type timeRange struct {
 	start string
 	end   string
 }
 
+type shiftLabel struct {
+	timeRange *timeRange
+	label     string
+}
+
 type nonWorkingDaySet struct {
 	nonWorkingDays []nonWorkingDay
 }
 
 type nonWorkingDay interface {
 	cover(time.Time) bool
+	describe(time.Time) string
 }
 
 type jpHoliday struct{}
@@ -88,64 +156,239 @@ type weekday time.Weekday
 
 var _ nonWorkingDay = weekday(time.Sunday)
 
-func NewShiftGenerator(tz *time.Location, since, until string, handoffTimes, include, nonWorkingDays []string) (*ShiftGenerator, error) {
-	if len(handoffTimes) == 0 {
-		return nil, errors.New("no handoff times provided")
+// WithDayTypeAnchor specifies how to classify shifts spanning working and non-working days.
+// "start" and "end" use the day on which the shift starts or ends, "majority" uses the day type
+// covering most of the shift, and "split" counts the shift proportionally to the time on each day type.
+func WithDayTypeAnchor(anchor string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.dayTypeAnchor = anchor
+	}
+}
+
+// WithDayClasses specifies named day classes in order of precedence.
+// Days not covered by any class fall back to the non-working days and then to working days.
+func WithDayClasses(dayClasses []DayClass) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.dayClasses = dayClasses
+	}
+}
+
+// WithExclude specifies conditions to exclude shifts matching the include conditions.
+// The format is the same as the include conditions.
+func WithExclude(exclude []string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.exclude = exclude
+	}
+}
+
+// WithSelect specifies a boolean expression to select shifts in addition to the include conditions
+func WithSelect(expression string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.selectExpr = expression
+	}
+}
+
+// WithShiftLabels specifies labels of shifts such as "day" and "night".
+// Each key is the time range of shifts (e.g. "17:00-05:00").
+func WithShiftLabels(shiftLabels map[string]string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.shiftLabels = shiftLabels
+	}
+}
+
+// WithDayHandoffTimes specifies handoff times for specific weekdays (e.g. "Sat") or day classes
+// (e.g. "non-working-days"). Handoff times for day classes take precedence over ones for weekdays.
+func WithDayHandoffTimes(dayHandoffTimes map[string][]string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.dayHandoffs = dayHandoffTimes
+	}
+}
+
+// WithEffectiveHandoffTimes specifies handoff times that take effect from specific dates.
+// The handoff times passed to NewShiftGenerator are used before the earliest date.
+func WithEffectiveHandoffTimes(effectiveHandoffTimes []EffectiveHandoffTimes) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.effectiveHandoffs = effectiveHandoffTimes
+	}
+}
+
+// WithHandoffCycle makes handoffs happen only every the given number of days from the anchor date
+// (e.g. 7 days from a Monday for weekly shifts). The anchor date defaults to since.
+func WithHandoffCycle(days int, anchor string) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.cycleDays = days
+		o.cycleAnchor = anchor
 	}
-	if !slices.IsSorted(handoffTimes) {
-		return nil, errors.New("handoff times must be sorted")
+}
+
+// WithClip makes shifts clipped at since and until so that partial shifts at the boundaries are counted
+// proportionally
+func WithClip(clip bool) ShiftGeneratorOption {
+	return func(o *shiftGeneratorOptions) {
+		o.clip = clip
+	}
+}
+
+// ParseTime parses a date (e.g. "2025-07-01"), a date and time (e.g. "2025-07-01 17:00"), or an RFC3339 timestamp
+func ParseTime(value string, tz *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, tz); err == nil {
+			return t.In(tz), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a date or time", value)
+}
+
+func NewShiftGenerator(tz *time.Location, since, until string, handoffTimes, include, nonWorkingDays []string, opts ...ShiftGeneratorOption) (*ShiftGenerator, error) {
+	o := &shiftGeneratorOptions{
+		dayTypeAnchor: string(dayTypeAnchorStart),
+	}
+	for _, opt := range opts {
+		opt(o)
 	}
 
-	nwds, err := newNonWorkingDaySet(nonWorkingDays)
+	anchor := dayTypeAnchor(o.dayTypeAnchor)
+	switch anchor {
+	case dayTypeAnchorStart, dayTypeAnchorEnd, dayTypeAnchorMajority, dayTypeAnchorSplit:
+	default:
+		return nil, fmt.Errorf("unknown day type anchor %q", o.dayTypeAnchor)
+	}
+
+	classifier, err := newDayClassifier(o.dayClasses, nonWorkingDays)
 	if err != nil {
 		return nil, err
 	}
 
-	includeConditions, err := buildIncludeConditions(include, handoffTimes, nwds)
+	sinceTime, err := ParseTime(since, tz)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid since value: %w", err)
+	}
+	untilTime, err := ParseTime(until, tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid until value: %w", err)
 	}
 
-	shiftDurations, err := buildShiftDurations(handoffTimes, tz)
+	schedule, err := newHandoffSchedule(handoffTimes, o, sinceTime, classifier)
 	if err != nil {
 		return nil, err
 	}
+	allHandoffTimes := schedule.allTimes()
 
-	sinceTime, err := time.ParseInLocation(time.DateOnly, since, tz)
+	includeConditions, err := buildIncludeConditions(include, allHandoffTimes, classifier)
 	if err != nil {
-		return nil, fmt.Errorf("invalid since value: %w", err)
+		return nil, err
 	}
-	untilTime, err := time.ParseInLocation(time.DateOnly, until, tz)
+
+	if o.selectExpr != "" {
+		c, err := newSelectCondition(o.selectExpr, schedule, classifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid select expression: %w", err)
+		}
+		includeConditions = append(includeConditions, c)
+	}
+
+	conditions, err := buildIncludeConditions(o.exclude, allHandoffTimes, classifier)
 	if err != nil {
-		return nil, fmt.Errorf("invalid until value: %w", err)
+		return nil, fmt.Errorf("invalid exclude condition: %w", err)
+	}
+	excludeConditions := make([]*excludeCondition, len(conditions))
+	for i, c := range conditions {
+		excludeConditions[i] = &excludeCondition{includeCondition: c, text: o.exclude[i]}
 	}
 
-	current, err := time.ParseInLocation(time.DateTime, since+" "+handoffTimes[0]+":00", tz)
+	shiftLabels, err := buildShiftLabels(o.shiftLabels, schedule.shiftRanges())
 	if err != nil {
 		return nil, err
 	}
 
+	// Start from the shift including since if shifts are clipped, otherwise from the first shift after since
+	findHandoff := schedule.first
+	if o.clip {
+		findHandoff = schedule.last
+	}
+	day, index, ok := findHandoff(sinceTime)
+	if !ok {
+		return nil, fmt.Errorf("no handoff times within %d days around %s", maxHandoffInterval, since)
+	}
+	start, end := sinceTime, untilTime
+	if !o.clip {
+		start = schedule.at(day, index)
+		lastDay, lastIndex, ok := schedule.first(untilTime)
+		if !ok {
+			return nil, fmt.Errorf("no handoff times within %d days around %s", maxHandoffInterval, until)
+		}
+		end = schedule.at(lastDay, lastIndex)
+	}
+
 	return &ShiftGenerator{
 		since:             sinceTime,
 		until:             untilTime,
-		current:           current,
-		index:             0,
-		shiftDurations:    shiftDurations,
+		start:             start,
+		current:           schedule.at(day, index),
+		day:               day,
+		index:             index,
+		end:               end,
+		clip:              o.clip,
+		handoffSchedule:   schedule,
 		includeConditions: includeConditions,
-		nonWorkingDaySet:  nwds,
+		excludeConditions: excludeConditions,
+		dayClassifier:     classifier,
+		dayTypeAnchor:     anchor,
+		shiftLabels:       shiftLabels,
 	}, nil
 }
 
+// Period returns the start of the first shift and the end of the last shift,
+// or since and until if shifts are clipped
+func (s *ShiftGenerator) Period() (time.Time, time.Time) {
+	return s.start, s.end
+}
+
+// Shifts returns shifts to be counted
 func (s *ShiftGenerator) Shifts() iter.Seq[*Shift] {
+	return func(yield func(v *Shift) bool) {
+		for shift := range s.AllShifts() {
+			if shift.Weight == 0 {
+				continue
+			}
+			if !yield(shift) {
+				return
+			}
+		}
+	}
+}
+
+// AllShifts returns all shifts including ones not to be counted, whose weights are zero.
+// ExcludedBy of a shift is set if the weight of the shift is reduced by an exclude condition.
+func (s *ShiftGenerator) AllShifts() iter.Seq[*Shift] {
 	return func(yield func(v *Shift) bool) {
 		for s.current.Before(s.until) {
-			shift := NewShift(s.current, s.current.Add(s.shiftDurations[s.index]))
+			next, ok := s.nextHandoff()
+			if !ok {
+				return
+			}
+			shift := NewShift(s.current, next)
+			if s.clip {
+				shift.clip(s.since, s.until)
+			}
 			s.current = shift.End
-			s.index = (s.index + 1) % len(s.shiftDurations)
-			if len(s.includeConditions) > 0 && !slices.ContainsFunc(s.includeConditions, func(c includeCondition) bool {
-				return c.match(shift)
-			}) {
-				continue
+			if len(s.includeConditions) > 0 || len(s.excludeConditions) > 0 {
+				shift.Weight = 0
+				excludedBy := ""
+				included := s.includedWeights(shift)
+				for _, class := range s.dayClassifier.names() {
+					if included[class] == 0 {
+						continue
+					}
+					if c := s.findExcludeCondition(shift, class); c != nil {
+						if excludedBy == "" {
+							excludedBy = c.text
+						}
+						continue
+					}
+					shift.Weight += included[class]
+				}
+				shift.ExcludedBy = excludedBy
 			}
 			if !yield(shift) {
 				return
@@ -154,66 +397,223 @@ func (s *ShiftGenerator) Shifts() iter.Seq[*Shift] {
 	}
 }
 
+// ShiftLabels returns the labels of shifts in the order of handoff times, or nil if no labels are specified
+func (s *ShiftGenerator) ShiftLabels() []string {
+	if len(s.shiftLabels) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(s.shiftLabels))
+	for _, sl := range s.shiftLabels {
+		if !slices.Contains(labels, sl.label) {
+			labels = append(labels, sl.label)
+		}
+	}
+	return labels
+}
+
+// ShiftLabel returns the label of the shift. If the shift has no label, its time range is returned.
+func (s *ShiftGenerator) ShiftLabel(shift *Shift) string {
+	for _, sl := range s.shiftLabels {
+		if sl.timeRange.match(shift) {
+			return sl.label
+		}
+	}
+	return shift.Start.Format("15:04") + "-" + shift.End.Format("15:04")
+}
+
+// nextHandoff advances the day and index to the next handoff and returns its time
+func (s *ShiftGenerator) nextHandoff() (time.Time, bool) {
+	day, index, ok := s.handoffSchedule.following(s.day, s.index)
+	if !ok {
+		return time.Time{}, false
+	}
+	s.day = day
+	s.index = index
+	return s.handoffSchedule.at(s.day, s.index), true
+}
+
 func (c *timeRange) match(shift *Shift) bool {
 	return c.start == shift.Start.Format("15:04") && c.end == shift.End.Format("15:04")
 }
 
-func (c *workingDaysIncludeCondition) match(shift *Shift) bool {
-	if c.nonWorkingDaySet.cover(shift.Start) {
-		return false
+// DayClasses returns the names of the day classes that shifts can be classified into,
+// or nil if no day classes are specified
+func (s *ShiftGenerator) DayClasses() []string {
+	if len(s.dayClassifier.classes) == 1 {
+		return nil
 	}
-	return len(c.timeRanges) == 0 || slices.ContainsFunc(c.timeRanges, func(tr *timeRange) bool {
-		return tr.match(shift)
+	return s.dayClassifier.names()
+}
+
+// DayClassWeights returns the proportions of the shift to be counted for each day class
+func (s *ShiftGenerator) DayClassWeights(shift *Shift) map[string]float64 {
+	weights := s.includedWeights(shift)
+	for class := range weights {
+		if s.findExcludeCondition(shift, class) != nil {
+			delete(weights, class)
+		}
+	}
+	return weights
+}
+
+func (s *ShiftGenerator) includedWeights(shift *Shift) map[string]float64 {
+	shares := s.dayClassShares(shift)
+	if len(s.includeConditions) == 0 {
+		return shares
+	}
+
+	weights := make(map[string]float64, len(shares))
+	for class, share := range shares {
+		if share > 0 && slices.ContainsFunc(s.includeConditions, func(c includeCondition) bool {
+			return c.match(shift, class)
+		}) {
+			weights[class] = share
+		}
+	}
+	return weights
+}
+
+func (s *ShiftGenerator) findExcludeCondition(shift *Shift, class string) *excludeCondition {
+	i := slices.IndexFunc(s.excludeConditions, func(c *excludeCondition) bool {
+		return c.match(shift, class)
 	})
+	if i == -1 {
+		return nil
+	}
+	return s.excludeConditions[i]
+}
+
+// dayClassShares returns the shares of the shift for each day class according to the day type anchor
+func (s *ShiftGenerator) dayClassShares(shift *Shift) map[string]float64 {
+	switch s.dayTypeAnchor {
+	case dayTypeAnchorEnd:
+		// Use the last moment of the shift so that a shift ending at midnight belongs to the previous day
+		return map[string]float64{s.dayClassifier.classify(shift.End.Add(-time.Nanosecond)): 1}
+	case dayTypeAnchorMajority:
+		durations := s.dayClassDurations(shift.Start, shift.End)
+		majority := s.dayClassifier.classify(shift.Start)
+		for _, class := range s.dayClassifier.names() {
+			if durations[class] > durations[majority] {
+				majority = class
+			}
+		}
+		return map[string]float64{majority: 1}
+	case dayTypeAnchorSplit:
+		// Split only the counted period because the shares are multiplied by the fraction of the clipped shift
+		start, end := shift.CountedPeriod()
+		shares := make(map[string]float64)
+		for class, d := range s.dayClassDurations(start, end) {
+			shares[class] = float64(d) / float64(end.Sub(start))
+		}
+		return shares
+	default:
+		return map[string]float64{s.dayClassifier.classify(shift.Start): 1}
+	}
 }
 
-func (c *nonWorkingDaysIncludeCondition) match(shift *Shift) bool {
-	if !c.nonWorkingDaySet.cover(shift.Start) {
-		return false
+func (s *ShiftGenerator) dayClassDurations(start, end time.Time) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for start.Before(end) {
+		y, m, d := start.Date()
+		next := time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+		if next.After(end) {
+			next = end
+		}
+		durations[s.dayClassifier.classify(start)] += next.Sub(start)
+		start = next
 	}
-	return len(c.timeRanges) == 0 || slices.ContainsFunc(c.timeRanges, func(tr *timeRange) bool {
+	return durations
+}
+
+func matchTimeRanges(timeRanges []*timeRange, shift *Shift) bool {
+	return len(timeRanges) == 0 || slices.ContainsFunc(timeRanges, func(tr *timeRange) bool {
 		return tr.match(shift)
 	})
 }
 
+func (c *workingDaysIncludeCondition) match(shift *Shift, class string) bool {
+	return class == workingDaysClass && matchTimeRanges(c.timeRanges, shift)
+}
+
+func (c *nonWorkingDaysIncludeCondition) match(shift *Shift, class string) bool {
+	return class != workingDaysClass && matchTimeRanges(c.timeRanges, shift)
+}
+
+func (c *dayClassIncludeCondition) match(shift *Shift, class string) bool {
+	return class == c.class && matchTimeRanges(c.timeRanges, shift)
+}
+
+func (c *weekdayIncludeCondition) match(shift *Shift, _ string) bool {
+	return shift.Start.Weekday() == c.weekday && matchTimeRanges(c.timeRanges, shift)
+}
+
+func (c *dateIncludeCondition) match(shift *Shift, _ string) bool {
+	y, m, d := shift.Start.Date()
+	return y == c.year && m == c.month && d == c.day && matchTimeRanges(c.timeRanges, shift)
+}
+
 func (hs *nonWorkingDaySet) cover(t time.Time) bool {
 	return slices.ContainsFunc(hs.nonWorkingDays, func(h nonWorkingDay) bool {
 		return h.cover(t)
 	})
 }
 
+func (hs *nonWorkingDaySet) matchedRules(t time.Time) []string {
+	rules := make([]string, 0)
+	for _, h := range hs.nonWorkingDays {
+		if h.cover(t) {
+			rules = append(rules, h.describe(t))
+		}
+	}
+	return rules
+}
+
 func (h *jpHoliday) cover(t time.Time) bool {
 	return holidayjp.IsHoliday(t)
 }
 
+func (h *jpHoliday) describe(t time.Time) string {
+	holiday, err := holidayjp.New(t)
+	if err != nil {
+		return "JP holidays"
+	}
+	return fmt.Sprintf("JP holidays (%s)", holiday.NameEn())
+}
+
 func (d date) cover(t time.Time) bool {
 	return t.Month() == d.month && t.Day() == d.day
 }
 
+func (d date) describe(_ time.Time) string {
+	return fmt.Sprintf("%s %d", d.month.String()[:3], d.day)
+}
+
 func (w weekday) cover(t time.Time) bool {
 	return time.Weekday(w) == t.Weekday()
 }
 
-func buildShiftDurations(handoffTimes []string, zone *time.Location) ([]time.Duration, error) {
-	times := make([]time.Time, len(handoffTimes), len(handoffTimes)+1)
-	for i, t := range handoffTimes {
-		var err error
-		times[i], err = time.ParseInLocation("15:04", t, zone)
-		if err != nil {
-			return nil, fmt.Errorf("invalid handoff time: %w", err)
+func (w weekday) describe(_ time.Time) string {
+	return time.Weekday(w).String()[:3]
+}
+
+func buildShiftLabels(labels map[string]string, shiftRanges []*timeRange) ([]*shiftLabel, error) {
+	shiftLabels := make([]*shiftLabel, 0, len(labels))
+	for _, r := range shiftRanges {
+		if label, ok := labels[r.start+"-"+r.end]; ok {
+			shiftLabels = append(shiftLabels, &shiftLabel{timeRange: r, label: label})
 		}
 	}
-	times = append(times, times[0].Add(24*time.Hour))
-
-	shiftDurations := make([]time.Duration, len(times)-1)
-	for i := 1; i <= len(shiftDurations); i++ {
-		shiftDurations[i-1] = times[i].Sub(times[i-1])
+	if len(shiftLabels) != len(labels) {
+		for _, r := range slices.Sorted(maps.Keys(labels)) {
+			if !slices.ContainsFunc(shiftLabels, func(sl *shiftLabel) bool { return sl.timeRange.start+"-"+sl.timeRange.end == r }) {
+				return nil, fmt.Errorf("time range of the shift label %q must be between consecutive handoff times", r)
+			}
+		}
 	}
-
-	return shiftDurations, nil
+	return shiftLabels, nil
 }
 
-func buildIncludeConditions(include, handoffTimes []string, nwds *nonWorkingDaySet) ([]includeCondition, error) {
+func buildIncludeConditions(include, handoffTimes []string, classifier *dayClassifier) ([]includeCondition, error) {
 	includeConditions := make([]includeCondition, 0)
 	for _, c := range include {
 		typeAndRange := strings.SplitN(c, ":", 2)
@@ -234,7 +634,7 @@ func buildIncludeConditions(include, handoffTimes []string, nwds *nonWorkingDayS
 				startTime := handoffTimes[i]
 				i = (i + 1) % len(handoffTimes)
 				timeRanges = append(timeRanges, &timeRange{start: startTime, end: handoffTimes[i]})
-				if strings.HasSuffix(c, typeAndRange[1]) {
+				if strings.HasSuffix(typeAndRange[1], handoffTimes[i]) {
 					found = true
 					break
 				}
@@ -242,14 +642,31 @@ func buildIncludeConditions(include, handoffTimes []string, nwds *nonWorkingDayS
 			if !found {
 				return nil, fmt.Errorf("end time in the include condition %q must match one of handoff times", c)
 			}
+			// A single shift may cover the whole range if handoff times differ depending on the day
+			if len(timeRanges) > 1 {
+				m := timeRangeRegexp.FindStringSubmatch(typeAndRange[1])
+				timeRanges = append(timeRanges, &timeRange{start: m[1], end: m[2]})
+			}
 		}
 
 		switch typeAndRange[0] {
-		case "working-days":
-			includeConditions = append(includeConditions, &workingDaysIncludeCondition{timeRanges: timeRanges, nonWorkingDaySet: nwds})
-		case "non-working-days":
-			includeConditions = append(includeConditions, &nonWorkingDaysIncludeCondition{timeRanges: timeRanges, nonWorkingDaySet: nwds})
+		case workingDaysClass:
+			includeConditions = append(includeConditions, &workingDaysIncludeCondition{timeRanges: timeRanges})
+		case nonWorkingDaysClass:
+			includeConditions = append(includeConditions, &nonWorkingDaysIncludeCondition{timeRanges: timeRanges})
 		default:
+			if slices.ContainsFunc(classifier.classes, func(dc *dayClass) bool { return dc.name == typeAndRange[0] }) {
+				includeConditions = append(includeConditions, &dayClassIncludeCondition{class: typeAndRange[0], timeRanges: timeRanges})
+				continue
+			}
+			if w, ok := weekdays[typeAndRange[0]]; ok {
+				includeConditions = append(includeConditions, &weekdayIncludeCondition{weekday: w, timeRanges: timeRanges})
+				continue
+			}
+			if t, err := time.Parse(time.DateOnly, typeAndRange[0]); err == nil {
+				includeConditions = append(includeConditions, &dateIncludeCondition{year: t.Year(), month: t.Month(), day: t.Day(), timeRanges: timeRanges})
+				continue
+			}
 			return nil, fmt.Errorf("unknown include type %q", typeAndRange[0])
 		}
 	}
@@ -21,6 +21,7 @@ func TestShiftGenerator_Shifts(t *testing.T) {
 		handoffTimes   []string
 		include        []string
 		nonWorkingDays []string
+		opts           []pd.ShiftGeneratorOption
 
 		want []pd.Shift
 	}{
@@ -170,6 +171,372 @@ func TestShiftGenerator_Shifts(t *testing.T) {
 				),
 			},
 		},
+		{
+			name:           "With start day type anchor",
+			since:          "2025-07-04",
+			until:          "2025-07-05",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("start")},
+			want:           []pd.Shift{},
+		},
+		{
+			name:           "With end day type anchor",
+			since:          "2025-07-04",
+			until:          "2025-07-05",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("end")},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With end day type anchor and a shift ending at midnight",
+			since:          "2025-07-04",
+			until:          "2025-07-05",
+			handoffTimes:   []string{"00:00", "12:00"},
+			include:        []string{"non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("end")},
+			want:           []pd.Shift{},
+		},
+		{
+			name:           "With majority day type anchor",
+			since:          "2025-07-04",
+			until:          "2025-07-07",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"working-days:17:00-05:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("majority")},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With split day type anchor",
+			since:          "2025-07-04",
+			until:          "2025-07-07",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"working-days:17:00-05:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("split")},
+			want: []pd.Shift{
+				*withWeight(pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				), 7.0/12),
+				*withWeight(pd.NewShift(
+					time.Date(2025, time.July, 6, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 7, 5, 0, 0, 0, jst),
+				), 5.0/12),
+			},
+		},
+		{
+			name:           "With day classes",
+			since:          "2025-07-18",
+			until:          "2025-07-22",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"holidays", "weekends:17:00-05:00"},
+			nonWorkingDays: []string{},
+			opts: []pd.ShiftGeneratorOption{pd.WithDayClasses([]pd.DayClass{
+				{Name: "holidays", Days: []string{"JP holidays"}},
+				{Name: "weekends", Days: []string{"Sat", "Sun"}},
+			})},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 19, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 20, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 20, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 21, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 21, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 21, 17, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 21, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 22, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With weekday and date conditions",
+			since:          "2025-12-29",
+			until:          "2026-01-01",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"Tue:17:00-05:00", "2025-12-31"},
+			nonWorkingDays: []string{},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.December, 30, 17, 0, 0, 0, jst),
+					time.Date(2025, time.December, 31, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.December, 31, 5, 0, 0, 0, jst),
+					time.Date(2025, time.December, 31, 17, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.December, 31, 17, 0, 0, 0, jst),
+					time.Date(2026, time.January, 1, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With exclude conditions",
+			since:          "2025-04-27",
+			until:          "2025-04-30",
+			handoffTimes:   []string{"10:00", "22:00"},
+			include:        []string{"non-working-days", "working-days:22:00-10:00"},
+			nonWorkingDays: []string{"Sat", "Sunday", "JP holidays"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithExclude([]string{"2025-04-29:10:00-22:00", "Sun:22:00-10:00"})},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.April, 27, 10, 0, 0, 0, jst),
+					time.Date(2025, time.April, 27, 22, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.April, 28, 22, 0, 0, 0, jst),
+					time.Date(2025, time.April, 29, 10, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.April, 29, 22, 0, 0, 0, jst),
+					time.Date(2025, time.April, 30, 10, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With exclude conditions and split day type anchor",
+			since:          "2025-07-04",
+			until:          "2025-07-05",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"working-days:17:00-05:00", "non-working-days"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts: []pd.ShiftGeneratorOption{
+				pd.WithDayTypeAnchor("split"),
+				pd.WithExclude([]string{"non-working-days"}),
+			},
+			want: []pd.Shift{
+				*withExcludedBy(withWeight(pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				), 7.0/12), "non-working-days"),
+			},
+		},
+		{
+			name:           "With select expression",
+			since:          "2025-07-03",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithSelect("nonWorking(start) || (start == '17:00' && nonWorking(end))")},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 6, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With select expression using functions",
+			since:          "2025-07-03",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"2025-07-05:05:00-17:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithSelect(`weekday(start) == "Fri" && index() == 1 && duration() >= 12 && dayClass(start) != 'non-working-days'`)},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With include spanning multiple handoff times",
+			since:          "2025-07-01",
+			until:          "2025-07-02",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{"working-days:05:00-05:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 1, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 1, 17, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 1, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 2, 5, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With handoff times for weekends",
+			since:          "2025-07-04",
+			until:          "2025-07-08",
+			handoffTimes:   []string{"09:00", "18:00"},
+			include:        []string{"working-days:09:00-09:00", "non-working-days:09:00-09:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayHandoffTimes(map[string][]string{"non-working-days": {"09:00"}})},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 9, 0, 0, 0, jst),
+					time.Date(2025, time.July, 4, 18, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 18, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 9, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 9, 0, 0, 0, jst),
+					time.Date(2025, time.July, 6, 9, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 6, 9, 0, 0, 0, jst),
+					time.Date(2025, time.July, 7, 9, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 7, 9, 0, 0, 0, jst),
+					time.Date(2025, time.July, 7, 18, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 7, 18, 0, 0, 0, jst),
+					time.Date(2025, time.July, 8, 9, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With handoff times for a weekday",
+			since:          "2025-07-04",
+			until:          "2025-07-06",
+			handoffTimes:   []string{"09:00", "18:00"},
+			include:        []string{"Sat:10:00-18:00"},
+			nonWorkingDays: []string{},
+			opts:           []pd.ShiftGeneratorOption{pd.WithDayHandoffTimes(map[string][]string{"sat": {"10:00", "18:00"}})},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 10, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 18, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With effective handoff times",
+			since:          "2025-07-31",
+			until:          "2025-08-02",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			opts: []pd.ShiftGeneratorOption{pd.WithEffectiveHandoffTimes([]pd.EffectiveHandoffTimes{
+				{From: "2025-08-01", Times: []string{"09:00", "21:00"}},
+			})},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 31, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 31, 17, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 31, 17, 0, 0, 0, jst),
+					time.Date(2025, time.August, 1, 9, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.August, 1, 9, 0, 0, 0, jst),
+					time.Date(2025, time.August, 1, 21, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.August, 1, 21, 0, 0, 0, jst),
+					time.Date(2025, time.August, 2, 9, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With since and until including times",
+			since:          "2025-07-04 12:00",
+			until:          "2025-07-05T12:00:00+09:00",
+			handoffTimes:   []string{"05:00", "17:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 4, 17, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 5, 5, 0, 0, 0, jst),
+					time.Date(2025, time.July, 5, 17, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With weekly handoff times",
+			since:          "2025-07-02",
+			until:          "2025-07-21",
+			handoffTimes:   []string{"Mon 10:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 7, 10, 0, 0, 0, jst),
+					time.Date(2025, time.July, 14, 10, 0, 0, 0, jst),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.July, 14, 10, 0, 0, 0, jst),
+					time.Date(2025, time.July, 21, 10, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With handoff times on multiple weekdays",
+			since:          "2025-07-07",
+			until:          "2025-07-14",
+			handoffTimes:   []string{"Mon 10:00", "Thu 10:00", "Thu 18:00"},
+			include:        []string{"working-days:18:00-10:00"},
+			nonWorkingDays: []string{},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 10, 18, 0, 0, 0, jst),
+					time.Date(2025, time.July, 14, 10, 0, 0, 0, jst),
+				),
+			},
+		},
+		{
+			name:           "With handoff cycle",
+			since:          "2025-07-10",
+			until:          "2025-08-04",
+			handoffTimes:   []string{"10:00"},
+			include:        []string{},
+			nonWorkingDays: []string{},
+			opts:           []pd.ShiftGeneratorOption{pd.WithHandoffCycle(14, "2025-06-23")},
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.July, 21, 10, 0, 0, 0, jst),
+					time.Date(2025, time.August, 4, 10, 0, 0, 0, jst),
+				),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -180,6 +547,7 @@ func TestShiftGenerator_Shifts(t *testing.T) {
 				tt.handoffTimes,
 				tt.include,
 				tt.nonWorkingDays,
+				tt.opts...,
 			)
 			if err != nil {
 				t.Fatal(err)
@@ -196,3 +564,283 @@ func TestShiftGenerator_Shifts(t *testing.T) {
 		})
 	}
 }
+
+func TestShiftGenerator_Shifts_DST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		tz    *time.Location
+		since string
+		until string
+		want  []pd.Shift
+	}{
+		{
+			name:  "Start of DST in America/New_York",
+			tz:    newYork,
+			since: "2025-03-08",
+			until: "2025-03-10",
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.March, 8, 5, 0, 0, 0, newYork),
+					time.Date(2025, time.March, 8, 17, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.March, 8, 17, 0, 0, 0, newYork),
+					time.Date(2025, time.March, 9, 5, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.March, 9, 5, 0, 0, 0, newYork),
+					time.Date(2025, time.March, 9, 17, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.March, 9, 17, 0, 0, 0, newYork),
+					time.Date(2025, time.March, 10, 5, 0, 0, 0, newYork),
+				),
+			},
+		},
+		{
+			name:  "End of DST in America/New_York",
+			tz:    newYork,
+			since: "2025-11-01",
+			until: "2025-11-03",
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.November, 1, 5, 0, 0, 0, newYork),
+					time.Date(2025, time.November, 1, 17, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.November, 1, 17, 0, 0, 0, newYork),
+					time.Date(2025, time.November, 2, 5, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.November, 2, 5, 0, 0, 0, newYork),
+					time.Date(2025, time.November, 2, 17, 0, 0, 0, newYork),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.November, 2, 17, 0, 0, 0, newYork),
+					time.Date(2025, time.November, 3, 5, 0, 0, 0, newYork),
+				),
+			},
+		},
+		{
+			name:  "Start of DST in Europe/Berlin",
+			tz:    berlin,
+			since: "2025-03-30",
+			until: "2025-03-31",
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.March, 30, 5, 0, 0, 0, berlin),
+					time.Date(2025, time.March, 30, 17, 0, 0, 0, berlin),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.March, 30, 17, 0, 0, 0, berlin),
+					time.Date(2025, time.March, 31, 5, 0, 0, 0, berlin),
+				),
+			},
+		},
+		{
+			name:  "End of DST in Europe/Berlin",
+			tz:    berlin,
+			since: "2025-10-25",
+			until: "2025-10-26",
+			want: []pd.Shift{
+				*pd.NewShift(
+					time.Date(2025, time.October, 25, 5, 0, 0, 0, berlin),
+					time.Date(2025, time.October, 25, 17, 0, 0, 0, berlin),
+				),
+				*pd.NewShift(
+					time.Date(2025, time.October, 25, 17, 0, 0, 0, berlin),
+					time.Date(2025, time.October, 26, 5, 0, 0, 0, berlin),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg, err := pd.NewShiftGenerator(tt.tz, tt.since, tt.until, []string{"05:00", "17:00"}, []string{}, []string{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			shifts := make([]pd.Shift, 0)
+			for shift := range sg.Shifts() {
+				shifts = append(shifts, *shift)
+			}
+
+			if !reflect.DeepEqual(shifts, tt.want) {
+				t.Errorf("shifts = %v, want %v", shifts, tt.want)
+			}
+		})
+	}
+}
+
+func TestShiftGenerator_ShiftLabel(t *testing.T) {
+	tests := []struct {
+		name           string
+		since          string
+		until          string
+		handoffTimes   []string
+		nonWorkingDays []string
+		opts           []pd.ShiftGeneratorOption
+		wantLabels     []string
+		wantShifts     []string
+	}{
+		{
+			name:           "With handoff times for weekends",
+			since:          "2025-07-04",
+			until:          "2025-07-08",
+			handoffTimes:   []string{"09:00", "18:00"},
+			nonWorkingDays: []string{"Sat", "Sun"},
+			opts: []pd.ShiftGeneratorOption{
+				pd.WithDayHandoffTimes(map[string][]string{"non-working-days": {"09:00"}}),
+				pd.WithShiftLabels(map[string]string{"09:00-18:00": "day", "18:00-09:00": "night", "09:00-09:00": "all day"}),
+			},
+			wantLabels: []string{"all day", "day", "night"},
+			wantShifts: []string{"day", "night", "all day", "all day", "day", "night"},
+		},
+		{
+			name:         "With handoff cycle",
+			since:        "2025-07-01",
+			until:        "2025-07-15",
+			handoffTimes: []string{"09:00"},
+			opts: []pd.ShiftGeneratorOption{
+				pd.WithHandoffCycle(7, ""),
+				pd.WithShiftLabels(map[string]string{"09:00-09:00": "week"}),
+			},
+			wantLabels: []string{"week"},
+			wantShifts: []string{"week", "week"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg, err := pd.NewShiftGenerator(time.UTC, tt.since, tt.until, tt.handoffTimes, []string{}, tt.nonWorkingDays, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sg.ShiftLabels(); !reflect.DeepEqual(got, tt.wantLabels) {
+				t.Errorf("ShiftLabels() = %v, want %v", got, tt.wantLabels)
+			}
+			got := make([]string, 0)
+			for shift := range sg.Shifts() {
+				got = append(got, sg.ShiftLabel(shift))
+			}
+			if !reflect.DeepEqual(got, tt.wantShifts) {
+				t.Errorf("ShiftLabel() = %v, want %v", got, tt.wantShifts)
+			}
+		})
+	}
+}
+
+func TestNewShiftGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		opts    []pd.ShiftGeneratorOption
+		wantErr string
+	}{
+		{
+			name:    "End time of include not matching handoff times",
+			include: []string{"working-days:17:00-09:00"},
+			wantErr: `end time in the include condition "working-days:17:00-09:00" must match one of handoff times`,
+		},
+		{
+			name:    "Unknown day type anchor",
+			opts:    []pd.ShiftGeneratorOption{pd.WithDayTypeAnchor("middle")},
+			wantErr: `unknown day type anchor "middle"`,
+		},
+		{
+			name:    "Shift label not between consecutive handoff times",
+			opts:    []pd.ShiftGeneratorOption{pd.WithShiftLabels(map[string]string{"05:00-17:00": "day", "05:00-05:00": "all"})},
+			wantErr: `time range of the shift label "05:00-05:00" must be between consecutive handoff times`,
+		},
+		{
+			name: "Shift label not produced by handoff times for a day",
+			opts: []pd.ShiftGeneratorOption{
+				pd.WithDayHandoffTimes(map[string][]string{"Sat": {"09:00"}}),
+				pd.WithShiftLabels(map[string]string{"17:00-09:00": "night", "05:00-09:00": "morning"}),
+			},
+			wantErr: `time range of the shift label "05:00-09:00" must be between consecutive handoff times`,
+		},
+		{
+			name:    "Unknown day for handoff times",
+			opts:    []pd.ShiftGeneratorOption{pd.WithDayHandoffTimes(map[string][]string{"weekends": {"09:00"}})},
+			wantErr: `unknown day "weekends" for handoff times`,
+		},
+		{
+			name:    "Unsorted handoff times for a day",
+			opts:    []pd.ShiftGeneratorOption{pd.WithDayHandoffTimes(map[string][]string{"Sat": {"18:00", "09:00"}})},
+			wantErr: `invalid handoff times for "Sat": handoff times must be sorted`,
+		},
+		{
+			name: "Duplicate effective date of handoff times",
+			opts: []pd.ShiftGeneratorOption{pd.WithEffectiveHandoffTimes([]pd.EffectiveHandoffTimes{
+				{From: "2025-08-01", Times: []string{"09:00", "21:00"}},
+				{From: "2025-08-01", Times: []string{"10:00", "22:00"}},
+			})},
+			wantErr: "duplicate effective date of handoff times: 2025-08-01",
+		},
+		{
+			name:    "Too long handoff cycle",
+			opts:    []pd.ShiftGeneratorOption{pd.WithHandoffCycle(400, "")},
+			wantErr: "handoff cycle days must be between 1 and 366",
+		},
+		{
+			name: "Unexpected token in select expression",
+			opts: []pd.ShiftGeneratorOption{pd.WithSelect("nonWorking(start) || )")},
+			wantErr: `invalid select expression: unexpected token ")" at position 22
+  nonWorking(start) || )
+                       ^`,
+		},
+		{
+			name: "Type mismatch in select expression",
+			opts: []pd.ShiftGeneratorOption{pd.WithSelect("nonWorking(start) && duration() == '12'")},
+			wantErr: `invalid select expression: cannot compare number with string at position 33
+  nonWorking(start) && duration() == '12'
+                                  ^`,
+		},
+		{
+			name: "Unknown function in select expression",
+			opts: []pd.ShiftGeneratorOption{pd.WithSelect("holiday(start)")},
+			wantErr: `invalid select expression: unknown function "holiday" at position 1
+  holiday(start)
+  ^`,
+		},
+		{
+			name: "Unterminated string in select expression",
+			opts: []pd.ShiftGeneratorOption{pd.WithSelect("start == '17:00")},
+			wantErr: `invalid select expression: unterminated string at position 10
+  start == '17:00
+           ^`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pd.NewShiftGenerator(time.UTC, "2025-07-01", "2025-07-02", []string{"05:00", "17:00"}, tt.include, []string{}, tt.opts...)
+			if err == nil {
+				t.Fatalf("err = nil, want %q", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("err = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func withWeight(shift *pd.Shift, weight float64) *pd.Shift {
+	shift.Weight = weight
+	return shift
+}
+
+func withExcludedBy(shift *pd.Shift, excludedBy string) *pd.Shift {
+	shift.ExcludedBy = excludedBy
+	return shift
+}
@@ -41,6 +41,35 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// CreateOverrideWithContext mocks base method.
+func (m *MockClient) CreateOverrideWithContext(ctx context.Context, id string, o pagerduty.Override) (*pagerduty.Override, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOverrideWithContext", ctx, id, o)
+	ret0, _ := ret[0].(*pagerduty.Override)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOverrideWithContext indicates an expected call of CreateOverrideWithContext.
+func (mr *MockClientMockRecorder) CreateOverrideWithContext(ctx, id, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOverrideWithContext", reflect.TypeOf((*MockClient)(nil).CreateOverrideWithContext), ctx, id, o)
+}
+
+// DeleteOverrideWithContext mocks base method.
+func (m *MockClient) DeleteOverrideWithContext(ctx context.Context, scheduleID, overrideID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOverrideWithContext", ctx, scheduleID, overrideID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOverrideWithContext indicates an expected call of DeleteOverrideWithContext.
+func (mr *MockClientMockRecorder) DeleteOverrideWithContext(ctx, scheduleID, overrideID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOverrideWithContext", reflect.TypeOf((*MockClient)(nil).DeleteOverrideWithContext), ctx, scheduleID, overrideID)
+}
+
 // GetScheduleWithContext mocks base method.
 func (m *MockClient) GetScheduleWithContext(ctx context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error) {
 	m.ctrl.T.Helper()
@@ -55,3 +84,18 @@ func (mr *MockClientMockRecorder) GetScheduleWithContext(ctx, id, o any) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleWithContext", reflect.TypeOf((*MockClient)(nil).GetScheduleWithContext), ctx, id, o)
 }
+
+// ListOverridesWithContext mocks base method.
+func (m *MockClient) ListOverridesWithContext(ctx context.Context, id string, o pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOverridesWithContext", ctx, id, o)
+	ret0, _ := ret[0].(*pagerduty.ListOverridesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOverridesWithContext indicates an expected call of ListOverridesWithContext.
+func (mr *MockClientMockRecorder) ListOverridesWithContext(ctx, id, o any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOverridesWithContext", reflect.TypeOf((*MockClient)(nil).ListOverridesWithContext), ctx, id, o)
+}